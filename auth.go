@@ -0,0 +1,289 @@
+package httpmock
+
+import (
+	"crypto/md5" //nolint: gosec
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// BasicAuthIs returns a [Matcher] checking that request contains
+// Basic authentication credentials user and password.
+//
+// The name of the returned [Matcher] is auto-generated (see [NewMatcher]).
+// To name it explicitly, use [Matcher.WithName] as in:
+//
+//	BasicAuthIs("bob", "secret").WithName("10-bob")
+func BasicAuthIs(user, password string) Matcher {
+	return NewMatcher("",
+		func(req *http.Request) bool {
+			u, p, ok := req.BasicAuth()
+			return ok && u == user && p == password
+		})
+}
+
+// bearerToken returns the Bearer token of req Authorization header.
+func bearerToken(req *http.Request) (string, bool) {
+	auth := req.Header.Get("Authorization")
+	if len(auth) < 7 || !strings.EqualFold(auth[:7], "Bearer ") {
+		return "", false
+	}
+	return strings.TrimSpace(auth[7:]), true
+}
+
+// BearerTokenIs returns a [Matcher] checking that request contains
+// the Bearer token token in its Authorization header.
+//
+// The name of the returned [Matcher] is auto-generated (see [NewMatcher]).
+// To name it explicitly, use [Matcher.WithName] as in:
+//
+//	BearerTokenIs("t0k3n").WithName("10-token")
+func BearerTokenIs(token string) Matcher {
+	return NewMatcher("",
+		func(req *http.Request) bool {
+			t, ok := bearerToken(req)
+			return ok && t == token
+		})
+}
+
+// unauthorized returns a 401 Unauthorized response with challenge as
+// WWW-Authenticate header.
+func unauthorized(req *http.Request, challenge string) *http.Response {
+	resp := NewStringResponse(http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized))
+	resp.Header.Set("WWW-Authenticate", challenge)
+	resp.Request = req
+	return resp
+}
+
+// RequireBasicAuth returns a new [Responder] based on r that calls r
+// only if the request contains Basic authentication credentials
+// present in credentials, a map of passwords indexed by user.
+// Otherwise it returns a 401 Unauthorized response with a
+// WWW-Authenticate header challenging the client for realm, as
+// described in RFC 7617.
+//
+//	httpmock.RegisterResponder("GET", "/admin",
+//	  httpmock.NewStringResponder(200, "OK").
+//	    RequireBasicAuth("admin", map[string]string{"bob": "secret"}))
+func (r Responder) RequireBasicAuth(realm string, credentials map[string]string) Responder {
+	challenge := fmt.Sprintf(`Basic realm=%q, charset="UTF-8"`, realm)
+	return func(req *http.Request) (*http.Response, error) {
+		user, password, ok := req.BasicAuth()
+		if ok {
+			expected, exists := credentials[user]
+			if exists && subtle.ConstantTimeCompare([]byte(password), []byte(expected)) == 1 {
+				return r(req)
+			}
+		}
+		return unauthorized(req, challenge), nil
+	}
+}
+
+// RequireBearerToken returns a new [Responder] based on r that calls
+// r only if the request contains one of tokens as Bearer token.
+// Otherwise it returns a 401 Unauthorized response with a
+// WWW-Authenticate header challenging the client for realm, as
+// described in RFC 6750. If a token is present but not valid, the
+// challenge contains the error="invalid_token" attribute.
+//
+//	httpmock.RegisterResponder("GET", "/api/me",
+//	  httpmock.NewStringResponder(200, "OK").RequireBearerToken("api", "t0k3n"))
+func (r Responder) RequireBearerToken(realm string, tokens ...string) Responder {
+	challenge := fmt.Sprintf(`Bearer realm=%q`, realm)
+	return func(req *http.Request) (*http.Response, error) {
+		token, ok := bearerToken(req)
+		if !ok {
+			return unauthorized(req, challenge), nil
+		}
+		for _, t := range tokens {
+			if subtle.ConstantTimeCompare([]byte(token), []byte(t)) == 1 {
+				return r(req)
+			}
+		}
+		return unauthorized(req,
+			challenge+`, error="invalid_token", error_description="The access token is invalid"`), nil
+	}
+}
+
+// parseAuthParams parses the comma separated auth-params of an
+// Authorization header value, as `a="x, y", b=z`.
+func parseAuthParams(s string) map[string]string {
+	params := map[string]string{}
+	for {
+		s = strings.TrimLeft(s, " \t,")
+		eq := strings.IndexByte(s, '=')
+		if eq <= 0 {
+			return params
+		}
+		key := strings.ToLower(strings.TrimSpace(s[:eq]))
+		s = strings.TrimLeft(s[eq+1:], " \t")
+
+		var value string
+		if strings.HasPrefix(s, `"`) {
+			var b strings.Builder
+			i := 1
+			for ; i < len(s) && s[i] != '"'; i++ {
+				if s[i] == '\\' && i+1 < len(s) {
+					i++
+				}
+				b.WriteByte(s[i])
+			}
+			value = b.String()
+			if i < len(s) {
+				i++
+			}
+			s = s[i:]
+		} else {
+			end := strings.IndexByte(s, ',')
+			if end < 0 {
+				end = len(s)
+			}
+			value = strings.TrimSpace(s[:end])
+			s = s[end:]
+		}
+		params[key] = value
+	}
+}
+
+// digestAuth contains the state of a [Responder.RequireDigestAuth]
+// wrapper: the issued nonces and the last nonce count seen for each.
+type digestAuth struct {
+	realm       string
+	algorithm   string
+	credentials map[string]string
+	opaque      string
+
+	mu     sync.Mutex
+	nonces map[string]uint64
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b) //nolint: errcheck
+	return hex.EncodeToString(b)
+}
+
+func (d *digestAuth) hash(parts ...string) string {
+	var h hash.Hash
+	if d.algorithm == "SHA-256" {
+		h = sha256.New()
+	} else {
+		h = md5.New() //nolint: gosec
+	}
+	h.Write([]byte(strings.Join(parts, ":"))) //nolint: errcheck
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (d *digestAuth) challenge(stale bool) string {
+	nonce := randomHex(16)
+	d.mu.Lock()
+	d.nonces[nonce] = 0
+	d.mu.Unlock()
+
+	c := fmt.Sprintf(`Digest realm=%q, qop="auth", algorithm=%s, nonce=%q, opaque=%q`,
+		d.realm, d.algorithm, nonce, d.opaque)
+	if stale {
+		c += ", stale=true"
+	}
+	return c
+}
+
+// check returns whether req Authorization header is valid, and if
+// not, whether it is because of an unknown or replayed nonce.
+func (d *digestAuth) check(req *http.Request) (ok, stale bool) {
+	auth := req.Header.Get("Authorization")
+	if len(auth) < 7 || !strings.EqualFold(auth[:7], "Digest ") {
+		return false, false
+	}
+	p := parseAuthParams(auth[7:])
+
+	password, exists := d.credentials[p["username"]]
+	if !exists || p["realm"] != d.realm || p["opaque"] != d.opaque ||
+		p["uri"] != req.URL.RequestURI() ||
+		(p["algorithm"] != "" && !strings.EqualFold(p["algorithm"], d.algorithm)) {
+		return false, false
+	}
+
+	ha1 := d.hash(p["username"], d.realm, password)
+	ha2 := d.hash(req.Method, p["uri"])
+	var expected string
+	switch p["qop"] {
+	case "auth":
+		expected = d.hash(ha1, p["nonce"], p["nc"], p["cnonce"], "auth", ha2)
+	case "":
+		expected = d.hash(ha1, p["nonce"], ha2)
+	default:
+		return false, false
+	}
+	if subtle.ConstantTimeCompare([]byte(p["response"]), []byte(expected)) != 1 {
+		return false, false
+	}
+
+	// The response is correct, now check the nonce is known and not replayed
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	last, known := d.nonces[p["nonce"]]
+	if !known {
+		return false, true
+	}
+	if p["qop"] == "auth" {
+		nc, err := strconv.ParseUint(p["nc"], 16, 64)
+		if err != nil || nc <= last {
+			return false, true
+		}
+		d.nonces[p["nonce"]] = nc
+	}
+	return true, false
+}
+
+// RequireDigestAuth returns a new [Responder] based on r that calls r
+// only if the request contains valid Digest authentication
+// credentials, as described in RFC 7616. credentials is a map of
+// passwords indexed by user.
+//
+// Without valid credentials, a 401 Unauthorized response is
+// returned, with a WWW-Authenticate header challenging the client
+// for realm with a new nonce, qop="auth" and algorithm. algorithm
+// can be "MD5" or "SHA-256"; if empty, "MD5" is used. Otherwise a
+// panic occurs.
+//
+// The client response hash is checked against the username, realm,
+// password, nonce, nc, cnonce, method and uri. Only nonces issued by
+// the returned [Responder] are accepted, and each nonce count (nc)
+// must be greater than the previous one used with the same nonce, so
+// replayed requests are rejected with a stale=true challenge.
+//
+//	httpmock.RegisterResponder("GET", "/dir/index.html",
+//	  httpmock.NewStringResponder(200, "OK").
+//	    RequireDigestAuth("testrealm@host.com", "MD5", map[string]string{"Mufasa": "Circle of Life"}))
+func (r Responder) RequireDigestAuth(realm, algorithm string, credentials map[string]string) Responder {
+	switch algorithm {
+	case "":
+		algorithm = "MD5"
+	case "MD5", "SHA-256":
+	default:
+		panic(fmt.Sprintf("RequireDigestAuth: unsupported algorithm %q", algorithm))
+	}
+
+	d := &digestAuth{
+		realm:       realm,
+		algorithm:   algorithm,
+		credentials: credentials,
+		opaque:      randomHex(16),
+		nonces:      map[string]uint64{},
+	}
+	return func(req *http.Request) (*http.Response, error) {
+		ok, stale := d.check(req)
+		if ok {
+			return r(req)
+		}
+		return unauthorized(req, d.challenge(stale)), nil
+	}
+}
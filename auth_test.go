@@ -0,0 +1,196 @@
+package httpmock_test
+
+import (
+	"crypto/md5" //nolint: gosec
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/td"
+
+	"github.com/jarcoal/httpmock"
+)
+
+func TestAuthMatchers(t *testing.T) {
+	assert, require := td.AssertRequire(t)
+
+	req, err := http.NewRequest("GET", "http://z.tld/", nil)
+	require.CmpNoError(err)
+
+	assert.False(httpmock.BasicAuthIs("bob", "secret").Check(req))
+	assert.False(httpmock.BearerTokenIs("t0k3n").Check(req))
+
+	req.SetBasicAuth("bob", "secret")
+	assert.True(httpmock.BasicAuthIs("bob", "secret").Check(req))
+	assert.False(httpmock.BasicAuthIs("bob", "other").Check(req))
+	assert.False(httpmock.BearerTokenIs("t0k3n").Check(req))
+
+	req.Header.Set("Authorization", "Bearer t0k3n")
+	assert.True(httpmock.BearerTokenIs("t0k3n").Check(req))
+	assert.False(httpmock.BearerTokenIs("other").Check(req))
+	assert.False(httpmock.BasicAuthIs("bob", "secret").Check(req))
+
+	req.Header.Set("Authorization", "bearer  t0k3n ")
+	assert.True(httpmock.BearerTokenIs("t0k3n").Check(req))
+}
+
+func TestRequireBasicAuth(t *testing.T) {
+	assert, require := td.AssertRequire(t)
+
+	responder := httpmock.NewStringResponder(200, "OK").
+		RequireBasicAuth("admin", map[string]string{"bob": "secret"})
+
+	for _, tc := range []struct {
+		user, password string
+		status         int
+	}{
+		{"", "", 401},
+		{"bob", "secret", 200},
+		{"bob", "bad", 401},
+		{"alice", "secret", 401},
+	} {
+		req, err := http.NewRequest("GET", "http://z.tld/admin", nil)
+		require.CmpNoError(err)
+		if tc.user != "" {
+			req.SetBasicAuth(tc.user, tc.password)
+		}
+		resp, err := responder(req)
+		require.CmpNoError(err)
+		assert.Cmp(resp.StatusCode, tc.status, "%s:%s", tc.user, tc.password)
+		if tc.status == 401 {
+			assert.Cmp(resp.Header.Get("WWW-Authenticate"), `Basic realm="admin", charset="UTF-8"`)
+		}
+	}
+}
+
+func TestRequireBearerToken(t *testing.T) {
+	assert, require := td.AssertRequire(t)
+
+	responder := httpmock.NewStringResponder(200, "OK").
+		RequireBearerToken("api", "t0k3n", "other")
+
+	for _, tc := range []struct {
+		auth      string
+		status    int
+		challenge string
+	}{
+		{"", 401, `Bearer realm="api"`},
+		{"Basic Ym9iOnNlY3JldA==", 401, `Bearer realm="api"`},
+		{"Bearer t0k3n", 200, ""},
+		{"Bearer other", 200, ""},
+		{"Bearer bad", 401, `Bearer realm="api", error="invalid_token", error_description="The access token is invalid"`},
+	} {
+		req, err := http.NewRequest("GET", "http://z.tld/api", nil)
+		require.CmpNoError(err)
+		if tc.auth != "" {
+			req.Header.Set("Authorization", tc.auth)
+		}
+		resp, err := responder(req)
+		require.CmpNoError(err)
+		assert.Cmp(resp.StatusCode, tc.status, tc.auth)
+		assert.Cmp(resp.Header.Get("WWW-Authenticate"), tc.challenge, tc.auth)
+	}
+}
+
+// digestAuthorization computes the Authorization header answering the
+// Digest challenge.
+func digestAuthorization(challenge, method, uri, user, password, nc string) string {
+	params := map[string]string{}
+	for _, m := range regexp.MustCompile(`(\w+)=(?:"([^"]*)"|([^,\s]*))`).FindAllStringSubmatch(challenge, -1) {
+		params[m[1]] = m[2] + m[3]
+	}
+
+	hf := func(parts ...string) string {
+		var h hash.Hash
+		if params["algorithm"] == "SHA-256" {
+			h = sha256.New()
+		} else {
+			h = md5.New() //nolint: gosec
+		}
+		h.Write([]byte(strings.Join(parts, ":"))) //nolint: errcheck
+		return hex.EncodeToString(h.Sum(nil))
+	}
+
+	const cnonce = "0a4f113b"
+	ha1 := hf(user, params["realm"], password)
+	ha2 := hf(method, uri)
+	response := hf(ha1, params["nonce"], nc, cnonce, "auth", ha2)
+	return fmt.Sprintf(`Digest username=%q, realm=%q, nonce=%q, uri=%q, algorithm=%s, qop=auth, nc=%s, cnonce=%q, response=%q, opaque=%q`,
+		user, params["realm"], params["nonce"], uri, params["algorithm"], nc, cnonce, response, params["opaque"])
+}
+
+func TestRequireDigestAuth(t *testing.T) {
+	assert, require := td.AssertRequire(t)
+
+	for _, algo := range []string{"", "SHA-256"} {
+		mt := httpmock.NewMockTransport()
+		client := &http.Client{Transport: mt}
+		mt.RegisterResponder("GET", "http://z.tld/dir/index.html",
+			httpmock.NewStringResponder(200, "OK").
+				RequireDigestAuth("testrealm@host.com", algo, map[string]string{"Mufasa": "Circle of Life"}))
+
+		const uri = "/dir/index.html?x=1"
+		do := func(auth string) *http.Response {
+			req, err := http.NewRequest("GET", "http://z.tld"+uri, nil)
+			require.CmpNoError(err)
+			if auth != "" {
+				req.Header.Set("Authorization", auth)
+			}
+			resp, err := client.Do(req)
+			require.CmpNoError(err)
+			return resp
+		}
+
+		expectedAlgo := algo
+		if expectedAlgo == "" {
+			expectedAlgo = "MD5"
+		}
+		challengeRe := `^Digest realm="testrealm@host.com", qop="auth", algorithm=` + expectedAlgo +
+			`, nonce="[0-9a-f]{32}", opaque="[0-9a-f]{32}"`
+
+		// First request, no credentials
+		resp := do("")
+		assert.Cmp(resp.StatusCode, 401)
+		challenge := resp.Header.Get("WWW-Authenticate")
+		assert.Cmp(challenge, td.Re(challengeRe+`\z`))
+
+		// Bad password
+		resp = do(digestAuthorization(challenge, "GET", uri, "Mufasa", "bad", "00000001"))
+		assert.Cmp(resp.StatusCode, 401)
+		assert.Cmp(resp.Header.Get("WWW-Authenticate"), td.Re(challengeRe+`\z`))
+
+		// Bad method
+		resp = do(digestAuthorization(challenge, "POST", uri, "Mufasa", "Circle of Life", "00000001"))
+		assert.Cmp(resp.StatusCode, 401)
+
+		// OK
+		resp = do(digestAuthorization(challenge, "GET", uri, "Mufasa", "Circle of Life", "00000001"))
+		assert.Cmp(resp.StatusCode, 200)
+		assertBody(assert, resp, "OK")
+
+		// Same nonce, next nc
+		resp = do(digestAuthorization(challenge, "GET", uri, "Mufasa", "Circle of Life", "00000002"))
+		assert.Cmp(resp.StatusCode, 200)
+
+		// Replay
+		resp = do(digestAuthorization(challenge, "GET", uri, "Mufasa", "Circle of Life", "00000002"))
+		assert.Cmp(resp.StatusCode, 401)
+		assert.Cmp(resp.Header.Get("WWW-Authenticate"), td.Re(challengeRe+`, stale=true\z`))
+
+		// Unknown nonce
+		unknown := regexp.MustCompile(`nonce="[0-9a-f]+"`).
+			ReplaceAllString(challenge, `nonce="0123456789abcdef0123456789abcdef"`)
+		resp = do(digestAuthorization(unknown, "GET", uri, "Mufasa", "Circle of Life", "00000001"))
+		assert.Cmp(resp.StatusCode, 401)
+		assert.Cmp(resp.Header.Get("WWW-Authenticate"), td.Re(challengeRe+`, stale=true\z`))
+	}
+
+	assert.CmpPanic(func() {
+		httpmock.NewStringResponder(200, "OK").RequireDigestAuth("x", "SHA-1", nil)
+	}, `RequireDigestAuth: unsupported algorithm "SHA-1"`)
+}
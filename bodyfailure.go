@@ -0,0 +1,173 @@
+package httpmock
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil" //nolint: staticcheck
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// wrapBody returns a new [Responder] based on r replacing the body of
+// its responses by the result of wrap.
+func (r Responder) wrapBody(wrap func(req *http.Request, resp *http.Response) (io.ReadCloser, error)) Responder {
+	return func(req *http.Request) (*http.Response, error) {
+		resp, err := r(req)
+		if err != nil || resp == nil {
+			return resp, err
+		}
+		nr := *resp
+		if nr.Body == nil {
+			nr.Body = http.NoBody
+		}
+		nr.Body, err = wrap(req, &nr)
+		if err != nil {
+			return nil, err
+		}
+		return &nr, nil
+	}
+}
+
+// BodyFailAfter returns a new [Responder] based on r whose response
+// bodies return err once n bytes have been read, or when the original
+// body ends before. If err is nil, [io.ErrUnexpectedEOF] is used.
+//
+//	httpmock.RegisterResponder("GET", "/export",
+//	  httpmock.NewStringResponder(200, bigCSV).
+//	    BodyFailAfter(1024, syscall.ECONNRESET))
+func (r Responder) BodyFailAfter(n int, err error) Responder {
+	if err == nil {
+		err = io.ErrUnexpectedEOF
+	}
+	return r.wrapBody(func(_ *http.Request, resp *http.Response) (io.ReadCloser, error) {
+		return &truncatedBody{body: resp.Body, remain: n, err: err}, nil
+	})
+}
+
+// BodyTruncate returns a new [Responder] based on r whose response
+// bodies end with [io.ErrUnexpectedEOF] after n bytes, while the
+// Content-Length header and [http.Response.ContentLength] announce
+// the complete length, as net/http does when the connection is closed
+// too early.
+//
+// If the original response does not have a known length, the body is
+// completely read to compute it.
+//
+//	httpmock.RegisterResponder("GET", "/file",
+//	  httpmock.NewStringResponder(200, "0123456789").BodyTruncate(4))
+func (r Responder) BodyTruncate(n int) Responder {
+	return r.wrapBody(func(_ *http.Request, resp *http.Response) (io.ReadCloser, error) {
+		body := resp.Body
+		if resp.ContentLength < 0 {
+			if bl, ok := body.(interface{ Len() int }); ok {
+				resp.ContentLength = int64(bl.Len())
+			} else {
+				b, err := ioutil.ReadAll(body)
+				body.Close()
+				if err != nil {
+					return nil, err
+				}
+				body = buffer{bytes.NewReader(b)}
+				resp.ContentLength = int64(len(b))
+			}
+		}
+		if resp.Header == nil {
+			resp.Header = http.Header{}
+		}
+		resp.Header = resp.Header.Clone()
+		resp.Header.Set("Content-Length", strconv.FormatInt(resp.ContentLength, 10))
+		return &truncatedBody{body: body, remain: n, err: io.ErrUnexpectedEOF}, nil
+	})
+}
+
+// blockingBody blocks once remain bytes of body have been read, until
+// ctx is done or the body is closed.
+type blockingBody struct {
+	body   io.ReadCloser
+	remain int
+	ctx    context.Context
+
+	mu        sync.Mutex // protects body, as Close can be called during a blocked Read
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+func (b *blockingBody) Read(p []byte) (int, error) {
+	b.mu.Lock()
+	if b.remain > 0 {
+		if len(p) > b.remain {
+			p = p[:b.remain]
+		}
+		n, err := b.body.Read(p)
+		b.remain -= n
+		if err != io.EOF {
+			b.mu.Unlock()
+			return n, err
+		}
+		b.remain = 0
+		if n > 0 {
+			b.mu.Unlock()
+			return n, nil
+		}
+	}
+	b.mu.Unlock()
+
+	select {
+	case <-b.ctx.Done():
+		return 0, b.ctx.Err()
+	case <-b.closed:
+		return 0, errStreamBodyClosed
+	}
+}
+
+func (b *blockingBody) Close() error {
+	b.closeOnce.Do(func() { close(b.closed) })
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.body.Close()
+}
+
+// BodyBlockAfter returns a new [Responder] based on r whose response
+// bodies block once n bytes have been read (or at their end if
+// before), as a server that stops sending data without closing the
+// connection. The blocked Read call returns when the request context
+// is done, with the context error, or when the body is closed.
+//
+//	httpmock.RegisterResponder("GET", "/events",
+//	  httpmock.NewStringResponder(200, "data: 1\n\n").BodyBlockAfter(100))
+func (r Responder) BodyBlockAfter(n int) Responder {
+	return r.wrapBody(func(req *http.Request, resp *http.Response) (io.ReadCloser, error) {
+		return &blockingBody{
+			body:   resp.Body,
+			remain: n,
+			ctx:    req.Context(),
+			closed: make(chan struct{}),
+		}, nil
+	})
+}
+
+// closeErrorBody returns err when closed.
+type closeErrorBody struct {
+	io.ReadCloser
+	err error
+}
+
+func (b *closeErrorBody) Close() error {
+	b.ReadCloser.Close() //nolint: errcheck
+	return b.err
+}
+
+// BodyCloseError returns a new [Responder] based on r whose response
+// bodies return err when closed. The content of the bodies is not
+// altered.
+//
+//	httpmock.RegisterResponder("GET", "/",
+//	  httpmock.NewStringResponder(200, "OK").
+//	    BodyCloseError(errors.New("connection lost")))
+func (r Responder) BodyCloseError(err error) Responder {
+	return r.wrapBody(func(_ *http.Request, resp *http.Response) (io.ReadCloser, error) {
+		return &closeErrorBody{ReadCloser: resp.Body, err: err}, nil
+	})
+}
@@ -0,0 +1,114 @@
+package httpmock_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil" //nolint: staticcheck
+	"net/http"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+
+	"github.com/jarcoal/httpmock"
+)
+
+func TestResponderBodyFailAfter(t *testing.T) {
+	assert, require := td.AssertRequire(t)
+
+	req, err := http.NewRequest("GET", "http://z.tld/", nil)
+	require.CmpNoError(err)
+
+	resp, err := httpmock.NewStringResponder(200, "0123456789").
+		BodyFailAfter(4, syscall.ECONNRESET)(req)
+	require.CmpNoError(err)
+	b, err := ioutil.ReadAll(resp.Body)
+	assert.Cmp(err, syscall.ECONNRESET)
+	assert.Cmp(string(b), "0123")
+
+	// Body shorter than n, default error
+	resp, err = httpmock.NewStringResponder(200, "01").BodyFailAfter(4, nil)(req)
+	require.CmpNoError(err)
+	b, err = ioutil.ReadAll(resp.Body)
+	assert.Cmp(err, io.ErrUnexpectedEOF)
+	assert.Cmp(string(b), "01")
+}
+
+func TestResponderBodyTruncate(t *testing.T) {
+	assert, require := td.AssertRequire(t)
+
+	mt := httpmock.NewMockTransport()
+	mt.RegisterResponder("GET", "http://z.tld/",
+		httpmock.NewStringResponder(200, "0123456789").BodyTruncate(4))
+	mt.RegisterResponder("GET", "http://z.tld/stream",
+		httpmock.NewStreamResponder(200, func(context.Context) ([]byte, error) {
+			return nil, io.EOF
+		}).BodyTruncate(0))
+	client := &http.Client{Transport: mt}
+
+	resp, err := client.Get("http://z.tld/")
+	require.CmpNoError(err)
+	assert.Cmp(resp.ContentLength, int64(10))
+	assert.Cmp(resp.Header.Get("Content-Length"), "10")
+	b, err := ioutil.ReadAll(resp.Body)
+	assert.Cmp(err, io.ErrUnexpectedEOF)
+	assert.Cmp(string(b), "0123")
+
+	// Length unknown, so computed
+	resp, err = client.Get("http://z.tld/stream")
+	require.CmpNoError(err)
+	assert.Cmp(resp.ContentLength, int64(0))
+	_, err = ioutil.ReadAll(resp.Body)
+	assert.Cmp(err, io.ErrUnexpectedEOF)
+}
+
+func TestResponderBodyBlockAfter(t *testing.T) {
+	assert, require := td.AssertRequire(t)
+
+	mt := httpmock.NewMockTransport()
+	mt.RegisterResponder("GET", "http://z.tld/",
+		httpmock.NewStringResponder(200, "0123456789").BodyBlockAfter(4))
+	client := &http.Client{Transport: mt}
+
+	// Context canceled
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequestWithContext(ctx, "GET", "http://z.tld/", nil)
+	require.CmpNoError(err)
+	resp, err := client.Do(req)
+	require.CmpNoError(err)
+
+	time.AfterFunc(10*time.Millisecond, cancel)
+	b, err := ioutil.ReadAll(resp.Body)
+	assert.Cmp(err, context.Canceled)
+	assert.Cmp(string(b), "0123")
+
+	// Body closed
+	resp, err = client.Get("http://z.tld/")
+	require.CmpNoError(err)
+	time.AfterFunc(10*time.Millisecond, func() { resp.Body.Close() })
+	b, err = ioutil.ReadAll(resp.Body)
+	assert.String(err, "http: read on closed response body")
+	assert.Cmp(string(b), "0123")
+
+	// Blocks at the end of a short body
+	resp, err = httpmock.NewStringResponder(200, "01").BodyBlockAfter(4)(req)
+	require.CmpNoError(err)
+	b, err = ioutil.ReadAll(resp.Body)
+	assert.Cmp(err, context.Canceled)
+	assert.Cmp(string(b), "01")
+}
+
+func TestResponderBodyCloseError(t *testing.T) {
+	assert, require := td.AssertRequire(t)
+
+	req, err := http.NewRequest("GET", "http://z.tld/", nil)
+	require.CmpNoError(err)
+
+	closeErr := errors.New("connection lost")
+	resp, err := httpmock.NewStringResponder(200, "OK").BodyCloseError(closeErr)(req)
+	require.CmpNoError(err)
+	assertBody(assert, resp, "OK")
+	assert.Cmp(resp.Body.Close(), closeErr)
+}
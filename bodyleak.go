@@ -0,0 +1,224 @@
+package httpmock
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// trackedBody wraps a response body handed out by a [MockTransport]
+// to record whether it has been read until EOF and closed.
+type trackedBody struct {
+	body       io.ReadCloser
+	method     string
+	url        string
+	calledFrom string
+
+	mu     sync.Mutex
+	eof    bool
+	closed bool
+}
+
+func (b *trackedBody) Read(p []byte) (int, error) {
+	n, err := b.body.Read(p)
+	if err == io.EOF {
+		b.mu.Lock()
+		b.eof = true
+		b.mu.Unlock()
+	}
+	return n, err
+}
+
+func (b *trackedBody) Close() error {
+	b.mu.Lock()
+	// An already drained body, as an empty one, is considered read
+	if bl, ok := b.body.(interface{ Len() int }); ok && bl.Len() == 0 {
+		b.eof = true
+	}
+	b.closed = true
+	b.mu.Unlock()
+	return b.body.Close()
+}
+
+// trackedRWBody is a [trackedBody] wrapping a body also implementing
+// [io.Writer], as the one of a [NewWebSocketResponder] response.
+type trackedRWBody struct {
+	*trackedBody
+}
+
+func (b trackedRWBody) Write(p []byte) (int, error) {
+	return b.body.(io.Writer).Write(p)
+}
+
+// leaked returns a [LeakedBody] if b has not been closed or read
+// until EOF.
+func (b *trackedBody) leaked() (LeakedBody, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed && b.eof {
+		return LeakedBody{}, false
+	}
+	return LeakedBody{
+		Method:     b.method,
+		URL:        b.url,
+		CalledFrom: b.calledFrom,
+		Closed:     b.closed,
+		ReadToEOF:  b.eof,
+	}, true
+}
+
+// LeakedBody describes a response body handed out by a
+// [MockTransport] that has not been closed or not read until EOF. See
+// [MockTransport.TrackBodies].
+type LeakedBody struct {
+	Method string
+	URL    string
+	// CalledFrom is the location of the code that sent the request,
+	// as " @PKG.FUNC() FILE:LINE". It can be empty.
+	CalledFrom string
+	Closed     bool
+	ReadToEOF  bool
+}
+
+// String returns a description of lb, as:
+//
+//	GET http://z.tld/path: body never closed, never read until EOF @PKG.FUNC() FILE:LINE
+func (lb LeakedBody) String() string {
+	var problems []string
+	if !lb.Closed {
+		problems = append(problems, "never closed")
+	}
+	if !lb.ReadToEOF {
+		problems = append(problems, "never read until EOF")
+	}
+	return fmt.Sprintf("%s %s: body %s%s", lb.Method, lb.URL, strings.Join(problems, ", "), lb.CalledFrom)
+}
+
+// requestCallSite returns a string like " @PKG.FUNC() FILE:LINE"
+// locating the first caller outside of httpmock and net/http
+// packages, or "" if not found.
+func requestCallSite() string {
+	pc := make([]uintptr, 64)
+	npc := runtime.Callers(2, pc)
+	frames := runtime.CallersFrames(pc[:npc])
+	for {
+		frame, more := frames.Next()
+		if strings.HasPrefix(frame.Function, "testing.") {
+			return ""
+		}
+		pkg := extractPackage(frame.Function)
+		if !ignorePackages[pkg] && pkg != "net/http" {
+			return fmt.Sprintf(" @%s() %s:%d", frame.Function, frame.File, frame.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+// trackBody returns resp with its body wrapped to be tracked, if
+// body tracking is enabled. Otherwise resp is returned as is.
+func (m *MockTransport) trackBody(req *http.Request, resp *http.Response) *http.Response {
+	if resp == nil || resp.Body == nil || resp.Body == http.NoBody {
+		return resp
+	}
+
+	m.mu.RLock()
+	track := m.trackBodies
+	m.mu.RUnlock()
+	if !track {
+		return resp
+	}
+
+	tb := &trackedBody{
+		body:       resp.Body,
+		method:     req.Method,
+		url:        req.URL.String(),
+		calledFrom: requestCallSite(),
+	}
+	if tb.method == "" {
+		tb.method = http.MethodGet
+	}
+
+	m.mu.Lock()
+	m.bodies = append(m.bodies, tb)
+	m.mu.Unlock()
+
+	nr := *resp
+	if _, ok := resp.Body.(io.Writer); ok {
+		nr.Body = trackedRWBody{tb}
+	} else {
+		nr.Body = tb
+	}
+	return &nr
+}
+
+// TrackBodies enables or disables the tracking of response bodies
+// handed out by m. When enabled, each response body returned by m is
+// recorded, so [MockTransport.LeakedBodies] and
+// [MockTransport.CheckBodies] can report the ones never closed or
+// never read until EOF, two common causes of connection leaks with a
+// real [http.Transport].
+//
+// Only bodies returned after the call to TrackBodies(true) are
+// tracked. Disabling tracking forgets all tracked bodies. Tracked
+// bodies implementing [io.Writer], as the [NewWebSocketResponder]
+// ones, still implement it.
+//
+//	mt := httpmock.NewMockTransport()
+//	mt.TrackBodies(true)
+//	t.Cleanup(func() {
+//	  if err := mt.CheckBodies(); err != nil {
+//	    t.Error(err)
+//	  }
+//	})
+func (m *MockTransport) TrackBodies(track bool) {
+	m.mu.Lock()
+	m.trackBodies = track
+	if !track {
+		m.bodies = nil
+	}
+	m.mu.Unlock()
+}
+
+// LeakedBodies returns the tracked response bodies not closed or not
+// read until EOF, in the order they have been handed out. Bodies are
+// only tracked once [MockTransport.TrackBodies] has been called.
+func (m *MockTransport) LeakedBodies() []LeakedBody {
+	m.mu.RLock()
+	bodies := append([]*trackedBody(nil), m.bodies...)
+	m.mu.RUnlock()
+
+	var leaked []LeakedBody
+	for _, b := range bodies {
+		if lb, ok := b.leaked(); ok {
+			leaked = append(leaked, lb)
+		}
+	}
+	return leaked
+}
+
+// CheckBodies returns an error listing the tracked response bodies
+// not closed or not read until EOF, or nil if there are none. See
+// [MockTransport.TrackBodies].
+func (m *MockTransport) CheckBodies() error {
+	leaked := m.LeakedBodies()
+	if len(leaked) == 0 {
+		return nil
+	}
+	var b strings.Builder
+	if len(leaked) == 1 {
+		b.WriteString("1 leaked response body:")
+	} else {
+		fmt.Fprintf(&b, "%d leaked response bodies:", len(leaked))
+	}
+	for _, lb := range leaked {
+		b.WriteString("\n\t")
+		b.WriteString(lb.String())
+	}
+	return errors.New(b.String())
+}
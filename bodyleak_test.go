@@ -0,0 +1,119 @@
+package httpmock_test
+
+import (
+	"io"
+	"io/ioutil" //nolint: staticcheck
+	"net/http"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/td"
+
+	"github.com/jarcoal/httpmock"
+)
+
+func TestMockTransportTrackBodies(t *testing.T) {
+	assert, require := td.AssertRequire(t)
+
+	mt := httpmock.NewMockTransport()
+	mt.RegisterResponder("GET", "http://z.tld/body", httpmock.NewStringResponder(200, "body"))
+	mt.RegisterResponder("GET", "http://z.tld/empty", httpmock.NewStringResponder(204, ""))
+	client := &http.Client{Transport: mt}
+
+	get := func(url string) *http.Response {
+		resp, err := client.Get(url)
+		require.CmpNoError(err)
+		return resp
+	}
+
+	// Not tracked yet
+	get("http://z.tld/body")
+	assert.Nil(mt.LeakedBodies())
+	assert.CmpNoError(mt.CheckBodies())
+
+	mt.TrackBodies(true)
+
+	// OK
+	resp := get("http://z.tld/body")
+	_, err := ioutil.ReadAll(resp.Body)
+	require.CmpNoError(err)
+	resp.Body.Close()
+
+	get("http://z.tld/empty").Body.Close()
+
+	// Leaks
+	get("http://z.tld/body")              // never closed, never read
+	get("http://z.tld/body").Body.Close() // never read
+	resp = get("http://z.tld/body?q=1")   // never closed
+	_, err = ioutil.ReadAll(resp.Body)
+	require.CmpNoError(err)
+
+	leaked := mt.LeakedBodies()
+	for i := range leaked {
+		assert.Cmp(leaked[i].CalledFrom,
+			td.Re(`^ @.*TestMockTransportTrackBodies\.func\d+\(\) .*bodyleak_test\.go:\d+\z`))
+		leaked[i].CalledFrom = ""
+	}
+	assert.Cmp(leaked, []httpmock.LeakedBody{
+		{Method: "GET", URL: "http://z.tld/body"},
+		{Method: "GET", URL: "http://z.tld/body", Closed: true},
+		{Method: "GET", URL: "http://z.tld/body?q=1", ReadToEOF: true},
+	})
+
+	assert.Cmp(mt.CheckBodies(), td.Re(`^3 leaked response bodies:
+	GET http://z.tld/body: body never closed, never read until EOF @.*
+	GET http://z.tld/body: body never read until EOF @.*
+	GET http://z.tld/body\?q=1: body never closed @.*\z`))
+
+	resp.Body.Close()
+	assert.Len(mt.LeakedBodies(), 2)
+
+	// Reset forgets tracked bodies, but keeps tracking
+	mt.Reset()
+	assert.CmpNoError(mt.CheckBodies())
+	mt.RegisterResponder("GET", "http://z.tld/body", httpmock.NewStringResponder(200, "body"))
+	get("http://z.tld/body")
+	assert.Cmp(mt.CheckBodies(), td.HasPrefix("1 leaked response body:\n\tGET http://z.tld/body: "))
+
+	// Disabling forgets everything
+	mt.TrackBodies(false)
+	assert.CmpNoError(mt.CheckBodies())
+}
+
+func TestMockTransportTrackBodiesWebSocket(t *testing.T) {
+	assert, require := td.AssertRequire(t)
+
+	mt := httpmock.NewMockTransport()
+	mt.TrackBodies(true)
+	mt.RegisterResponder("GET", "http://z.tld/ws",
+		httpmock.NewWebSocketResponder(func(peer *httpmock.WebSocketPeer) {
+			_, msg, err := peer.ReadMessage()
+			if err == nil {
+				peer.WriteText("echo: " + string(msg)) //nolint: errcheck
+			}
+		}))
+
+	req, err := http.NewRequest("GET", "http://z.tld/ws", nil)
+	require.CmpNoError(err)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+
+	resp, err := mt.RoundTrip(req)
+	require.CmpNoError(err)
+	require.Cmp(resp.StatusCode, http.StatusSwitchingProtocols)
+
+	rwc, ok := resp.Body.(io.ReadWriteCloser)
+	require.True(ok, "body is still a io.ReadWriteCloser")
+
+	writeClientFrame(t, rwc, true, httpmock.WebSocketText, []byte("hi"))
+	_, opcode, payload := readServerFrame(t, rwc)
+	assert.Cmp(opcode, httpmock.WebSocketText)
+	assert.Cmp(string(payload), "echo: hi")
+
+	require.CmpNoError(rwc.Close())
+	assert.Cmp(mt.LeakedBodies(), td.Bag(
+		td.Struct(httpmock.LeakedBody{Method: "GET", URL: "http://z.tld/ws", Closed: true},
+			td.StructFields{"CalledFrom": td.Contains("TestMockTransportTrackBodiesWebSocket")}),
+	))
+}
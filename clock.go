@@ -0,0 +1,256 @@
+package httpmock
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock is the time source used by time-based responders, like the
+// ones returned by [Responder.Delay] or [Responder.RateLimit].
+//
+// See [MockTransport.SetClock] and [VirtualClock].
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// After waits for the duration to elapse and then sends the
+	// current time on the returned channel.
+	After(d time.Duration) <-chan time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// SystemClock is the [Clock] based on the real time, as provided by
+// the [time] package. It is the default one.
+var SystemClock Clock = systemClock{}
+
+// clockKeyType is used by MockTransport.RoundTrip to pass its clock
+// to responders.
+type clockKeyType struct{}
+
+var clockKey = clockKeyType{}
+
+// clockOf returns the clock of the MockTransport handling req, or
+// SystemClock if none has been set.
+func clockOf(req *http.Request) Clock {
+	if req != nil {
+		if clock, ok := req.Context().Value(clockKey).(Clock); ok {
+			return clock
+		}
+	}
+	return SystemClock
+}
+
+// sleep waits for d using clock, unless req context is canceled
+// before. In this case the context error is returned.
+func sleep(req *http.Request, clock Clock, d time.Duration) error {
+	return sleepContext(req.Context(), clock, d)
+}
+
+// sleepContext is like sleep, but stops as soon as ctx is done.
+func sleepContext(ctx context.Context, clock Clock, d time.Duration) error {
+	var after <-chan time.Time
+	if vc, ok := clock.(*VirtualClock); ok {
+		// do not leave a pending timer behind us if canceled
+		t := vc.after(d)
+		defer vc.stop(t)
+		after = t.ch
+	} else {
+		after = clock.After(d)
+	}
+
+	select {
+	case <-after:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+type virtualTimer struct {
+	when time.Time
+	ch   chan time.Time
+}
+
+// VirtualClock is a [Clock] whose time only changes when
+// [VirtualClock.Advance] or [VirtualClock.Set] is called. Used in
+// conjunction with [MockTransport.SetClock], it allows to test slow
+// APIs without slowing down tests:
+//
+//	clock := httpmock.NewVirtualClock(time.Now())
+//	httpmock.SetClock(clock)
+//	httpmock.RegisterResponder("GET", "/foo/bar",
+//	  httpmock.NewStringResponder(200, "{}").Delay(time.Minute),
+//	)
+//
+//	ctx, cancel := clock.WithTimeout(context.Background(), 30*time.Second)
+//	defer cancel()
+//	req, _ := http.NewRequestWithContext(ctx, "GET", "http://z.tld/foo/bar", nil)
+//
+//	go func() {
+//	  clock.BlockUntil(2)            // Delay timer + timeout timer
+//	  clock.Advance(30 * time.Second) // timeout expires, Delay still waiting
+//	}()
+//	_, err := http.DefaultClient.Do(req) // err wraps context.DeadlineExceeded
+//
+// The zero VirtualClock is not usable, use [NewVirtualClock] instead.
+type VirtualClock struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	now    time.Time
+	timers []*virtualTimer
+}
+
+// NewVirtualClock returns a new [*VirtualClock] set to now.
+func NewVirtualClock(now time.Time) *VirtualClock {
+	c := &VirtualClock{now: now}
+	c.cond = sync.NewCond(&c.mu)
+	return c
+}
+
+// Now implements [Clock] interface.
+func (c *VirtualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// After implements [Clock] interface. The returned channel receives
+// the virtual time as soon as the clock is advanced to or beyond
+// c.Now()+d. If d <= 0, the channel is ready immediately.
+func (c *VirtualClock) After(d time.Duration) <-chan time.Time {
+	return c.after(d).ch
+}
+
+func (c *VirtualClock) after(d time.Duration) *virtualTimer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &virtualTimer{when: c.now.Add(d), ch: make(chan time.Time, 1)}
+	if d <= 0 {
+		t.ch <- c.now
+		return t
+	}
+	c.timers = append(c.timers, t)
+	c.cond.Broadcast()
+	return t
+}
+
+// stop removes t from pending timers.
+func (c *VirtualClock) stop(t *virtualTimer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, cur := range c.timers {
+		if cur == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			return
+		}
+	}
+}
+
+// Advance moves the virtual time forward by d, firing, in
+// chronological order, all timers expiring in the meantime.
+func (c *VirtualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	now := c.now.Add(d)
+	c.mu.Unlock()
+	c.Set(now)
+}
+
+// Set sets the virtual time to now, firing, in chronological order,
+// all timers expiring before or at now. Setting a time before the
+// current virtual time does not fire any timer.
+func (c *VirtualClock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	sort.SliceStable(c.timers, func(i, j int) bool {
+		return c.timers[i].when.Before(c.timers[j].when)
+	})
+
+	i := 0
+	for ; i < len(c.timers) && !c.timers[i].when.After(now); i++ {
+		c.timers[i].ch <- c.timers[i].when
+	}
+	c.timers = append(c.timers[:0], c.timers[i:]...)
+	c.now = now
+}
+
+// PendingTimers returns the number of timers not fired yet, typically
+// the number of goroutines waiting for the virtual time to advance.
+func (c *VirtualClock) PendingTimers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
+
+// BlockUntil blocks until at least n timers are pending. It is
+// useful to ensure that responders are waiting for the virtual time
+// to advance before calling [VirtualClock.Advance].
+func (c *VirtualClock) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for len(c.timers) < n {
+		c.cond.Wait()
+	}
+}
+
+// WithTimeout is like [context.WithTimeout] but the returned
+// context deadline is based on c virtual time: the context is
+// canceled with [context.DeadlineExceeded] as soon as c is advanced
+// to or beyond c.Now()+timeout.
+func (c *VirtualClock) WithTimeout(parent context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	timer := c.after(timeout)
+	ctx := &virtualDeadlineCtx{
+		Context:  parent,
+		deadline: timer.when,
+		done:     make(chan struct{}),
+	}
+
+	stop := make(chan struct{})
+	go func() {
+		defer c.stop(timer)
+		select {
+		case <-timer.ch:
+			ctx.cancel(context.DeadlineExceeded)
+		case <-parent.Done():
+			ctx.cancel(parent.Err())
+		case <-stop:
+			ctx.cancel(context.Canceled)
+		}
+	}()
+
+	var once sync.Once
+	return ctx, func() { once.Do(func() { close(stop) }) }
+}
+
+type virtualDeadlineCtx struct {
+	context.Context
+	deadline time.Time
+	done     chan struct{}
+	mu       sync.Mutex
+	err      error
+}
+
+func (c *virtualDeadlineCtx) cancel(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err == nil {
+		c.err = err
+		close(c.done)
+	}
+}
+
+func (c *virtualDeadlineCtx) Deadline() (time.Time, bool) { return c.deadline, true }
+func (c *virtualDeadlineCtx) Done() <-chan struct{}       { return c.done }
+
+func (c *virtualDeadlineCtx) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
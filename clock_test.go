@@ -0,0 +1,110 @@
+package httpmock_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+
+	"github.com/jarcoal/httpmock"
+)
+
+func TestVirtualClock(t *testing.T) {
+	assert := td.Assert(t)
+
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := httpmock.NewVirtualClock(start)
+	assert.Cmp(clock.Now(), start)
+
+	ch0 := clock.After(0)
+	assert.Cmp(len(ch0), 1)
+
+	ch2 := clock.After(2 * time.Second)
+	ch1 := clock.After(time.Second)
+	assert.Cmp(clock.PendingTimers(), 2)
+
+	clock.Advance(1500 * time.Millisecond)
+	assert.Cmp(clock.Now(), start.Add(1500*time.Millisecond))
+	assert.Cmp(len(ch2), 0)
+	assert.Cmp(<-ch1, start.Add(time.Second))
+	assert.Cmp(clock.PendingTimers(), 1)
+
+	clock.Set(start.Add(time.Hour))
+	assert.Cmp(<-ch2, start.Add(2*time.Second))
+	assert.Cmp(clock.PendingTimers(), 0)
+
+	done := make(chan struct{})
+	go func() {
+		clock.BlockUntil(1)
+		close(done)
+	}()
+	clock.After(time.Second)
+	<-done
+}
+
+func TestVirtualClockWithTimeout(t *testing.T) {
+	assert := td.Assert(t)
+
+	clock := httpmock.NewVirtualClock(time.Now())
+
+	ctx, cancel := clock.WithTimeout(context.Background(), time.Minute)
+	deadline, ok := ctx.Deadline()
+	assert.True(ok)
+	assert.Cmp(deadline, clock.Now().Add(time.Minute))
+	assert.CmpNoError(ctx.Err())
+
+	clock.Advance(time.Minute)
+	<-ctx.Done()
+	assert.Cmp(ctx.Err(), context.DeadlineExceeded)
+	cancel()
+	assert.Cmp(ctx.Err(), context.DeadlineExceeded)
+
+	ctx, cancel = clock.WithTimeout(context.Background(), time.Minute)
+	cancel()
+	<-ctx.Done()
+	assert.Cmp(ctx.Err(), context.Canceled)
+
+	parent, parentCancel := context.WithCancel(context.Background())
+	ctx, cancel = clock.WithTimeout(parent, time.Minute)
+	defer cancel()
+	parentCancel()
+	<-ctx.Done()
+	assert.Cmp(ctx.Err(), context.Canceled)
+}
+
+func TestMockTransportSetClock(t *testing.T) {
+	assert, require := td.AssertRequire(t)
+
+	clock := httpmock.NewVirtualClock(time.Now())
+
+	mt := httpmock.NewMockTransport()
+	mt.SetClock(clock)
+	mt.RegisterResponder("GET", "/slow",
+		httpmock.NewStringResponder(200, "slow").Delay(time.Hour))
+	client := &http.Client{Transport: mt}
+
+	// Delay elapses
+	go func() {
+		clock.BlockUntil(1)
+		clock.Advance(time.Hour)
+	}()
+	resp, err := client.Get("http://z.tld/slow")
+	require.CmpNoError(err)
+	assertBody(assert, resp, "slow")
+
+	// Timeout expires before Delay
+	ctx, cancel := clock.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "GET", "http://z.tld/slow", nil)
+	require.CmpNoError(err)
+
+	go func() {
+		clock.BlockUntil(2)
+		clock.Advance(time.Minute)
+	}()
+	_, err = client.Do(req)
+	assert.True(errors.Is(err, context.DeadlineExceeded))
+}
@@ -0,0 +1,241 @@
+package httpmock
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"io/ioutil" //nolint: staticcheck
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// qualityValue is an item of an Accept-like header, as "gzip;q=0.8".
+type qualityValue struct {
+	value  string
+	params map[string]string
+	q      float64
+}
+
+// parseQualityValues parses the comma separated items of an
+// Accept-like header, as described in RFC 9110 section 12.4.2. Items
+// without q parameter have a quality of 1. The returned items are
+// in the header order.
+func parseQualityValues(header string) []qualityValue {
+	var items []qualityValue
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		value := strings.ToLower(strings.TrimSpace(fields[0]))
+		if value == "" {
+			continue
+		}
+		item := qualityValue{value: value, q: 1}
+		for _, f := range fields[1:] {
+			key, val := f, ""
+			if i := strings.IndexByte(f, '='); i >= 0 {
+				key, val = f[:i], f[i+1:]
+			}
+			key = strings.ToLower(strings.TrimSpace(key))
+			val = strings.Trim(strings.TrimSpace(val), `"`)
+			if key == "q" {
+				if q, err := strconv.ParseFloat(val, 64); err == nil && q >= 0 && q <= 1 {
+					item.q = q
+				}
+				continue
+			}
+			if item.params == nil {
+				item.params = map[string]string{}
+			}
+			item.params[key] = val
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// negotiateEncoding returns the content coding among "gzip" and
+// "deflate" preferred by acceptEncoding, or "" if none is acceptable.
+// gzip wins ties.
+func negotiateEncoding(acceptEncoding string) string {
+	qualities := map[string]float64{}
+	wildcard := -1.0
+	for _, item := range parseQualityValues(acceptEncoding) {
+		switch item.value {
+		case "*":
+			wildcard = item.q
+		case "x-gzip":
+			qualities["gzip"] = item.q
+		default:
+			qualities[item.value] = item.q
+		}
+	}
+
+	best, bestQ := "", 0.0
+	for _, encoding := range []string{"gzip", "deflate"} {
+		q, ok := qualities[encoding]
+		if !ok {
+			q = wildcard
+		}
+		if q > bestQ {
+			best, bestQ = encoding, q
+		}
+	}
+	return best
+}
+
+// compress returns body compressed using encoding, "gzip" or
+// "deflate". As described in RFC 9110, "deflate" means the zlib
+// format.
+func compress(encoding string, body []byte) []byte {
+	var (
+		buf bytes.Buffer
+		w   io.WriteCloser
+	)
+	if encoding == "gzip" {
+		w = gzip.NewWriter(&buf)
+	} else {
+		w = zlib.NewWriter(&buf)
+	}
+	w.Write(body) //nolint: errcheck
+	w.Close()     //nolint: errcheck
+	return buf.Bytes()
+}
+
+// decompress returns body decoded according to the Content-Encoding
+// header value encodings. Codings are undone in the reverse order they
+// have been applied. Unknown codings are left as is, so body is
+// returned unchanged.
+func decompress(encodings string, body []byte) ([]byte, error) {
+	codings := strings.Split(encodings, ",")
+	for i := len(codings) - 1; i >= 0; i-- {
+		var (
+			r   io.ReadCloser
+			err error
+		)
+		switch strings.ToLower(strings.TrimSpace(codings[i])) {
+		case "", "identity":
+			continue
+		case "gzip", "x-gzip":
+			r, err = gzip.NewReader(bytes.NewReader(body))
+		case "deflate":
+			// zlib format, but some clients send raw deflate data
+			r, err = zlib.NewReader(bytes.NewReader(body))
+			if err != nil {
+				r, err = flate.NewReader(bytes.NewReader(body)), nil
+			}
+		default:
+			return body, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		body, err = ioutil.ReadAll(r)
+		r.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return body, nil
+}
+
+// readDecodedBody is like readBody but transparently decompresses
+// the body if req has a gzip or deflate Content-Encoding header.
+func readDecodedBody(req *http.Request) ([]byte, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	if encodings := req.Header.Get("Content-Encoding"); encodings != "" {
+		return decompress(encodings, body)
+	}
+	return body, nil
+}
+
+func (r Responder) compress(transparent bool) Responder {
+	return func(req *http.Request) (*http.Response, error) {
+		resp, err := r(req)
+		if err != nil || resp == nil ||
+			resp.Body == nil || resp.Body == http.NoBody ||
+			resp.StatusCode == http.StatusNoContent ||
+			resp.StatusCode == http.StatusNotModified ||
+			resp.Header.Get("Content-Encoding") != "" {
+			return resp, err
+		}
+
+		acceptEncoding, ok := req.Header["Accept-Encoding"]
+		if !ok && transparent {
+			// net/http Transport would have asked for gzip and then
+			// transparently decompressed the response
+			nr := *resp
+			nr.Header = nr.Header.Clone()
+			nr.Header.Del("Content-Length")
+			nr.ContentLength = -1
+			nr.Uncompressed = true
+			return &nr, nil
+		}
+
+		encoding := negotiateEncoding(strings.Join(acceptEncoding, ","))
+		if encoding == "" {
+			return resp, nil
+		}
+
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		body = compress(encoding, body)
+
+		nr := *resp
+		nr.Body = buffer{bytes.NewReader(body)}
+		nr.ContentLength = int64(len(body))
+		if nr.Header == nil {
+			nr.Header = http.Header{}
+		}
+		nr.Header = nr.Header.Clone()
+		nr.Header.Set("Content-Encoding", encoding)
+		nr.Header.Add("Vary", "Accept-Encoding")
+		if nr.Header.Get("Content-Length") != "" {
+			nr.Header.Set("Content-Length", strconv.Itoa(len(body)))
+		}
+		return &nr, nil
+	}
+}
+
+// Compress returns a new [Responder] based on r that compresses the
+// response body using gzip or deflate, depending on the request
+// Accept-Encoding header and its q-values. gzip is preferred when
+// both are equally acceptable. Content-Encoding header is set
+// accordingly and Accept-Encoding is added to Vary header.
+//
+// The response is left untouched if the request does not accept any
+// of these codings, if the response has no body, a 204 or 304 status
+// or already a Content-Encoding header.
+//
+// Note that net/http Transport adds "Accept-Encoding: gzip" itself
+// when the request has no such header and then transparently
+// decompresses the response. As [MockTransport] replaces it, the
+// request is seen as is by r. See [Responder.CompressTransparent] to
+// emulate this behavior.
+//
+//	httpmock.RegisterResponder("GET", "/big",
+//	  httpmock.NewStringResponder(200, bigBody).Compress())
+func (r Responder) Compress() Responder {
+	return r.compress(false)
+}
+
+// CompressTransparent is like [Responder.Compress] but behaves as
+// net/http Transport does when the request has no Accept-Encoding
+// header: the body is left uncompressed, the Content-Length header is
+// removed, [http.Response.ContentLength] is set to -1 and
+// [http.Response.Uncompressed] is set to true. When the request
+// contains an Accept-Encoding header, the client is in charge of the
+// decompression, so it behaves exactly as [Responder.Compress].
+//
+//	httpmock.RegisterResponder("GET", "/big",
+//	  httpmock.NewStringResponder(200, bigBody).CompressTransparent())
+func (r Responder) CompressTransparent() Responder {
+	return r.compress(true)
+}
@@ -0,0 +1,160 @@
+package httpmock_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io/ioutil" //nolint: staticcheck
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/td"
+
+	"github.com/jarcoal/httpmock"
+)
+
+func TestResponderCompress(t *testing.T) {
+	assert, require := td.AssertRequire(t)
+
+	body := strings.Repeat("hello world! ", 100)
+	responder := httpmock.NewStringResponder(200, body).SetContentLength().Compress()
+
+	get := func(acceptEncoding ...string) *http.Response {
+		req, err := http.NewRequest("GET", "http://z.tld/", nil)
+		require.CmpNoError(err)
+		if len(acceptEncoding) > 0 {
+			req.Header.Set("Accept-Encoding", acceptEncoding[0])
+		}
+		resp, err := responder(req)
+		require.CmpNoError(err)
+		return resp
+	}
+
+	// gzip
+	resp := get("gzip, deflate")
+	assert.Cmp(resp.Header.Get("Content-Encoding"), "gzip")
+	assert.Cmp(resp.Header.Get("Vary"), "Accept-Encoding")
+	assert.Cmp(resp.Header.Get("Content-Length"), td.Not(""))
+	assert.Lt(resp.ContentLength, int64(len(body)))
+	zr, err := gzip.NewReader(resp.Body)
+	require.CmpNoError(err)
+	b, err := ioutil.ReadAll(zr)
+	require.CmpNoError(err)
+	assert.Cmp(string(b), body)
+	assert.False(resp.Uncompressed)
+
+	// deflate preferred using q-values
+	resp = get("gzip;q=0.5, deflate")
+	assert.Cmp(resp.Header.Get("Content-Encoding"), "deflate")
+	fr, err := zlib.NewReader(resp.Body)
+	require.CmpNoError(err)
+	b, err = ioutil.ReadAll(fr)
+	require.CmpNoError(err)
+	assert.Cmp(string(b), body)
+
+	// wildcard
+	assert.Cmp(get("*").Header.Get("Content-Encoding"), "gzip")
+	assert.Cmp(get("*, gzip;q=0").Header.Get("Content-Encoding"), "deflate")
+
+	// no acceptable coding
+	for _, ae := range []string{"br", "identity", "gzip;q=0, deflate;q=0"} {
+		resp = get(ae)
+		assert.Cmp(resp.Header.Get("Content-Encoding"), "", ae)
+		assertBody(assert, resp, body)
+	}
+
+	// no Accept-Encoding header
+	resp = get()
+	assert.Cmp(resp.Header.Get("Content-Encoding"), "")
+	assertBody(assert, resp, body)
+
+	// already encoded
+	resp, err = httpmock.NewStringResponder(200, "xxx").
+		HeaderSet(http.Header{"Content-Encoding": {"br"}}).
+		Compress()(&http.Request{Header: http.Header{"Accept-Encoding": {"gzip"}}})
+	require.CmpNoError(err)
+	assert.Cmp(resp.Header.Get("Content-Encoding"), "br")
+	assertBody(assert, resp, "xxx")
+
+	// no content
+	resp, err = httpmock.NewStringResponder(204, "").
+		Compress()(&http.Request{Header: http.Header{"Accept-Encoding": {"gzip"}}})
+	require.CmpNoError(err)
+	assert.Cmp(resp.Header.Get("Content-Encoding"), "")
+}
+
+func TestResponderCompressTransparent(t *testing.T) {
+	assert, require := td.AssertRequire(t)
+
+	mt := httpmock.NewMockTransport()
+	mt.RegisterResponder("GET", "http://z.tld/",
+		httpmock.NewStringResponder(200, "hello").SetContentLength().CompressTransparent())
+	client := &http.Client{Transport: mt}
+
+	// As net/http Transport: transparently decompressed
+	resp, err := client.Get("http://z.tld/")
+	require.CmpNoError(err)
+	assert.True(resp.Uncompressed)
+	assert.Cmp(resp.ContentLength, int64(-1))
+	assert.Cmp(resp.Header.Get("Content-Length"), "")
+	assert.Cmp(resp.Header.Get("Content-Encoding"), "")
+	assertBody(assert, resp, "hello")
+
+	// Explicitly asked, the client decompresses itself
+	req, err := http.NewRequest("GET", "http://z.tld/", nil)
+	require.CmpNoError(err)
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, err = client.Do(req)
+	require.CmpNoError(err)
+	assert.False(resp.Uncompressed)
+	assert.Cmp(resp.Header.Get("Content-Encoding"), "gzip")
+	zr, err := gzip.NewReader(resp.Body)
+	require.CmpNoError(err)
+	b, err := ioutil.ReadAll(zr)
+	require.CmpNoError(err)
+	assert.Cmp(string(b), "hello")
+}
+
+func TestBodyMatchersDecompress(t *testing.T) {
+	assert, require := td.AssertRequire(t)
+
+	var gz, zl bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write([]byte(`{"name":"Bob","age":42}`)) //nolint: errcheck
+	zw.Close()                                  //nolint: errcheck
+	fw := zlib.NewWriter(&zl)
+	fw.Write([]byte(`{"name":"Bob","age":42}`)) //nolint: errcheck
+	fw.Close()                                  //nolint: errcheck
+
+	for encoding, body := range map[string][]byte{"gzip": gz.Bytes(), "deflate": zl.Bytes()} {
+		mt := httpmock.NewMockTransport()
+		mt.RegisterMatcherResponder("POST", "http://z.tld/",
+			httpmock.BodyContainsString(`"Bob"`).
+				And(httpmock.JSONBodyIs(map[string]interface{}{"name": "Bob", "age": 42})),
+			httpmock.NewStringResponder(200, "OK"))
+
+		req, err := http.NewRequest("POST", "http://z.tld/", bytes.NewReader(body))
+		require.CmpNoError(err)
+		req.Header.Set("Content-Encoding", encoding)
+		resp, err := mt.RoundTrip(req)
+		require.CmpNoError(err, encoding)
+		assertBody(assert, resp, "OK")
+
+		// The raw body is still available to the responder
+		assert.True(httpmock.BodyContainsString("Bob").Check(req), encoding)
+		b, err := ioutil.ReadAll(req.Body)
+		require.CmpNoError(err)
+		assert.Cmp(b, body, encoding)
+	}
+
+	// Corrupted body never matches
+	req, err := http.NewRequest("POST", "http://z.tld/", strings.NewReader("Bob"))
+	require.CmpNoError(err)
+	req.Header.Set("Content-Encoding", "gzip")
+	assert.False(httpmock.BodyContainsString("Bob").Check(req))
+
+	// Unknown codings are left as is
+	req.Header.Set("Content-Encoding", "br")
+	assert.True(httpmock.BodyContainsString("Bob").Check(req))
+}
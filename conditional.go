@@ -0,0 +1,234 @@
+package httpmock
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil" //nolint: staticcheck
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Conditional configures [Responder.Conditional].
+type Conditional struct {
+	// ETag is the entity tag of the response, as `"v1"` or `W/"v1"`
+	// for a weak one. If quotes are missing, they are added. If empty,
+	// a strong entity tag is computed from the response body, unless
+	// NoETag is true.
+	ETag string
+	// WeakETag makes the computed entity tag weak.
+	WeakETag bool
+	// NoETag disables the ETag header. If-Match and If-None-Match
+	// request headers can then only match "*".
+	NoETag bool
+	// LastModified is the last modification time of the response. If
+	// zero, no Last-Modified header is sent and If-Modified-Since and
+	// If-Unmodified-Since request headers are ignored.
+	LastModified time.Time
+	// CacheControl, if non-empty, is the Cache-Control header value,
+	// as "max-age=60" or "no-cache".
+	CacheControl string
+	// Expires, if non-zero, is the delay after which the response is
+	// considered stale. It is used to compute the Expires header.
+	Expires time.Duration
+	// Clock is the time source used for the Expires header. If nil,
+	// the clock of the [MockTransport] is used (see
+	// [MockTransport.SetClock]).
+	Clock Clock
+}
+
+// etagOf returns the quoted entity tag etag.
+func etagOf(etag string) string {
+	if strings.HasPrefix(etag, `"`) || strings.HasPrefix(etag, `W/"`) {
+		return etag
+	}
+	return `"` + etag + `"`
+}
+
+// etagMatch returns whether etag matches one of the comma separated
+// entity tags of header, or if header is "*". An empty etag only
+// matches "*". If weak is true, the
+// weak comparison is used, the strong one otherwise, as described in
+// RFC 9110 section 8.8.3.2.
+func etagMatch(header, etag string, weak bool) bool {
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+	if etag == "" || (!weak && strings.HasPrefix(etag, "W/")) {
+		return false
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if strings.HasPrefix(candidate, "W/") {
+			if !weak {
+				continue
+			}
+			candidate = candidate[2:]
+		}
+		if candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// notModifiedHeaders lists the headers a 304 Not Modified response
+// must contain, as described in RFC 9110 section 15.4.5.
+var notModifiedHeaders = []string{
+	"Cache-Control", "Content-Location", "Date", "Etag", "Expires", "Last-Modified", "Vary",
+}
+
+// evaluate returns the status code resulting of the evaluation of
+// req preconditions, as described in RFC 9110 section 13.2.2: 0 if
+// the request has to be performed, 304 or 412 otherwise.
+func (c *Conditional) evaluate(req *http.Request, etag string, lastModified time.Time) int {
+	hasLastModified := !lastModified.IsZero()
+	isGetOrHead := req.Method == http.MethodGet || req.Method == http.MethodHead
+
+	if ifMatch := req.Header.Get("If-Match"); ifMatch != "" {
+		if !etagMatch(ifMatch, etag, false) {
+			return http.StatusPreconditionFailed
+		}
+	} else if hasLastModified {
+		if date, err := http.ParseTime(req.Header.Get("If-Unmodified-Since")); err == nil &&
+			lastModified.After(date) {
+			return http.StatusPreconditionFailed
+		}
+	}
+
+	if ifNoneMatch := req.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		if etagMatch(ifNoneMatch, etag, true) {
+			if isGetOrHead {
+				return http.StatusNotModified
+			}
+			return http.StatusPreconditionFailed
+		}
+	} else if hasLastModified && isGetOrHead {
+		if date, err := http.ParseTime(req.Header.Get("If-Modified-Since")); err == nil &&
+			!lastModified.After(date) {
+			return http.StatusNotModified
+		}
+	}
+	return 0
+}
+
+// Conditional returns a new [Responder] based on r that adds
+// validators and caching headers to r successful (2xx) responses and
+// honors the conditional request headers If-Match,
+// If-Unmodified-Since, If-None-Match and If-Modified-Since, as
+// described in RFC 9110 section 13.
+//
+// Depending on c, the ETag, Last-Modified, Cache-Control and Expires
+// headers are set. When a precondition fails, a 412 Precondition
+// Failed response is returned instead. When a GET or HEAD request
+// validators match, a 304 Not Modified response without body is
+// returned instead, containing the ETag, Last-Modified,
+// Cache-Control, Expires and Vary headers of r response.
+//
+// Non-2xx responses of r are returned as is.
+//
+// When c.ETag is set or c.NoETag is true, the validators are known
+// in advance, so preconditions are evaluated before calling r, as
+// RFC 9110 section 13.2.1 requires: a failing precondition returns a
+// 412 response without calling r, so r side effects do not occur. r
+// is still called to build a 304 response, as only GET and HEAD
+// requests get it. When the entity tag is computed from the response
+// body, r has to be called first, then preconditions are evaluated.
+//
+//	httpmock.RegisterResponder("GET", "/doc",
+//	  httpmock.NewStringResponder(200, "content").
+//	    Conditional(httpmock.Conditional{
+//	      LastModified: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+//	      CacheControl: "max-age=60",
+//	    }))
+func (r Responder) Conditional(c Conditional) Responder {
+	lastModified := c.LastModified.UTC().Truncate(time.Second)
+	return func(req *http.Request) (*http.Response, error) {
+		if c.NoETag || c.ETag != "" {
+			var etag string
+			if !c.NoETag {
+				etag = etagOf(c.ETag)
+			}
+			if c.evaluate(req, etag, lastModified) == http.StatusPreconditionFailed {
+				return preconditionFailed(req), nil
+			}
+		}
+
+		resp, err := r(req)
+		if err != nil || resp == nil || resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return resp, err
+		}
+
+		nr := *resp
+		if nr.Header == nil {
+			nr.Header = http.Header{}
+		}
+		nr.Header = nr.Header.Clone()
+
+		var etag string
+		if !c.NoETag {
+			if c.ETag != "" {
+				etag = etagOf(c.ETag)
+			} else {
+				var body []byte
+				if nr.Body != nil {
+					body, err = ioutil.ReadAll(nr.Body)
+					nr.Body.Close()
+					if err != nil {
+						return nil, err
+					}
+					nr.Body = buffer{bytes.NewReader(body)}
+				}
+				sum := sha256.Sum256(body)
+				etag = `"` + hex.EncodeToString(sum[:16]) + `"`
+				if c.WeakETag {
+					etag = "W/" + etag
+				}
+			}
+			nr.Header.Set("ETag", etag)
+		}
+		if !lastModified.IsZero() {
+			nr.Header.Set("Last-Modified", lastModified.Format(http.TimeFormat))
+		}
+		if c.CacheControl != "" {
+			nr.Header.Set("Cache-Control", c.CacheControl)
+		}
+		if c.Expires != 0 {
+			clock := c.Clock
+			if clock == nil {
+				clock = clockOf(req)
+			}
+			nr.Header.Set("Expires", clock.Now().Add(c.Expires).UTC().Format(http.TimeFormat))
+		}
+
+		status := c.evaluate(req, etag, lastModified)
+		if status == 0 {
+			return &nr, nil
+		}
+
+		if nr.Body != nil {
+			nr.Body.Close()
+		}
+		if status == http.StatusPreconditionFailed {
+			return preconditionFailed(req), nil
+		}
+		cr := NewStringResponse(status, "")
+		cr.Body = http.NoBody
+		for _, key := range notModifiedHeaders {
+			if values, ok := nr.Header[key]; ok {
+				cr.Header[key] = values
+			}
+		}
+		cr.Request = req
+		return cr, nil
+	}
+}
+
+// preconditionFailed returns a 412 Precondition Failed response.
+func preconditionFailed(req *http.Request) *http.Response {
+	resp := NewStringResponse(http.StatusPreconditionFailed, http.StatusText(http.StatusPreconditionFailed))
+	resp.Request = req
+	return resp
+}
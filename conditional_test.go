@@ -0,0 +1,145 @@
+package httpmock_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+
+	"github.com/jarcoal/httpmock"
+)
+
+func TestResponderConditional(t *testing.T) {
+	assert, require := td.AssertRequire(t)
+
+	lastModified := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	clock := httpmock.NewVirtualClock(time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC))
+
+	responder := httpmock.NewStringResponder(200, "content").
+		HeaderSet(http.Header{"Vary": {"Accept"}}).
+		Conditional(httpmock.Conditional{
+			LastModified: lastModified,
+			CacheControl: "max-age=60",
+			Expires:      time.Minute,
+			Clock:        clock,
+		})
+
+	do := func(method string, headers map[string]string) *http.Response {
+		req, err := http.NewRequest(method, "http://z.tld/doc", nil)
+		require.CmpNoError(err)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		resp, err := responder(req)
+		require.CmpNoError(err)
+		return resp
+	}
+
+	resp := do("GET", nil)
+	assert.Cmp(resp.StatusCode, 200)
+	etag := resp.Header.Get("ETag")
+	assert.Cmp(etag, td.Re(`^"[0-9a-f]{32}"\z`))
+	assert.Cmp(resp.Header.Get("Last-Modified"), "Fri, 02 Jan 2026 03:04:05 GMT")
+	assert.Cmp(resp.Header.Get("Cache-Control"), "max-age=60")
+	assert.Cmp(resp.Header.Get("Expires"), "Sun, 18 Oct 2026 10:01:00 GMT")
+	assertBody(assert, resp, "content")
+
+	// The computed ETag is stable
+	assert.Cmp(do("GET", nil).Header.Get("ETag"), etag)
+
+	before := lastModified.Add(-time.Hour).Format(http.TimeFormat)
+	after := lastModified.Add(time.Hour).Format(http.TimeFormat)
+
+	for _, tc := range []struct {
+		name    string
+		method  string
+		headers map[string]string
+		status  int
+	}{
+		{"If-None-Match match", "GET", map[string]string{"If-None-Match": etag}, 304},
+		{"If-None-Match weak match", "GET", map[string]string{"If-None-Match": `"x", W/` + etag}, 304},
+		{"If-None-Match star", "HEAD", map[string]string{"If-None-Match": "*"}, 304},
+		{"If-None-Match no match", "GET", map[string]string{"If-None-Match": `"x"`}, 200},
+		{"If-None-Match on PUT", "PUT", map[string]string{"If-None-Match": "*"}, 412},
+		{"If-Modified-Since after", "GET", map[string]string{"If-Modified-Since": after}, 304},
+		{"If-Modified-Since same", "GET", map[string]string{"If-Modified-Since": lastModified.Format(http.TimeFormat)}, 304},
+		{"If-Modified-Since before", "GET", map[string]string{"If-Modified-Since": before}, 200},
+		{"If-Modified-Since on POST", "POST", map[string]string{"If-Modified-Since": after}, 200},
+		{"If-Modified-Since ignored", "GET", map[string]string{"If-None-Match": `"x"`, "If-Modified-Since": after}, 200},
+		{"If-Match match", "PUT", map[string]string{"If-Match": etag}, 200},
+		{"If-Match star", "PUT", map[string]string{"If-Match": "*"}, 200},
+		{"If-Match no match", "PUT", map[string]string{"If-Match": `"x"`}, 412},
+		{"If-Match weak", "PUT", map[string]string{"If-Match": "W/" + etag}, 412},
+		{"If-Unmodified-Since after", "PUT", map[string]string{"If-Unmodified-Since": after}, 200},
+		{"If-Unmodified-Since before", "PUT", map[string]string{"If-Unmodified-Since": before}, 412},
+		{"If-Unmodified-Since ignored", "PUT", map[string]string{"If-Match": etag, "If-Unmodified-Since": before}, 200},
+	} {
+		resp := do(tc.method, tc.headers)
+		assert.Cmp(resp.StatusCode, tc.status, tc.name)
+		switch tc.status {
+		case 304:
+			assert.Cmp(resp.Body, http.NoBody, tc.name)
+			assert.Cmp(resp.Header, http.Header{
+				"Etag":          {etag},
+				"Last-Modified": {"Fri, 02 Jan 2026 03:04:05 GMT"},
+				"Cache-Control": {"max-age=60"},
+				"Expires":       {"Sun, 18 Oct 2026 10:01:00 GMT"},
+				"Vary":          {"Accept"},
+			}, tc.name)
+		case 412:
+			assertBody(assert, resp, "Precondition Failed")
+		}
+	}
+
+	// Explicit weak ETag
+	responder = httpmock.NewStringResponder(200, "content").
+		Conditional(httpmock.Conditional{ETag: `W/"v1"`})
+	resp = do("GET", map[string]string{"If-None-Match": `"v1"`})
+	assert.Cmp(resp.StatusCode, 304)
+	assert.Cmp(resp.Header.Get("ETag"), `W/"v1"`)
+	assert.Cmp(do("PUT", map[string]string{"If-Match": `"v1"`}).StatusCode, 412)
+
+	// Unquoted ETag
+	responder = httpmock.NewStringResponder(200, "content").
+		Conditional(httpmock.Conditional{ETag: "v2"})
+	assert.Cmp(do("GET", nil).Header.Get("ETag"), `"v2"`)
+
+	// No validators at all
+	responder = httpmock.NewStringResponder(200, "content").
+		Conditional(httpmock.Conditional{NoETag: true, CacheControl: "no-cache"})
+	resp = do("GET", map[string]string{"If-None-Match": `"x"`, "If-Modified-Since": after})
+	assert.Cmp(resp.StatusCode, 200)
+	assert.Cmp(resp.Header.Get("ETag"), "")
+	assert.Cmp(resp.Header.Get("Last-Modified"), "")
+	assert.Cmp(resp.Header.Get("Cache-Control"), "no-cache")
+	assert.Cmp(do("PUT", map[string]string{"If-Match": `"x"`}).StatusCode, 412)
+	assert.Cmp(do("PUT", map[string]string{"If-Match": "*"}).StatusCode, 200)
+
+	// Preconditions evaluated before calling the responder
+	calls := 0
+	responder = httpmock.Responder(func(req *http.Request) (*http.Response, error) {
+		calls++
+		return httpmock.NewStringResponse(200, "updated"), nil
+	}).Conditional(httpmock.Conditional{ETag: "v1"})
+	assert.Cmp(do("PUT", map[string]string{"If-Match": `"x"`}).StatusCode, 412)
+	assert.Cmp(calls, 0)
+	assert.Cmp(do("PUT", map[string]string{"If-Match": `"v1"`}).StatusCode, 200)
+	assert.Cmp(calls, 1)
+
+	// nil response
+	responder = httpmock.Responder(func(*http.Request) (*http.Response, error) { return nil, nil }).
+		Conditional(httpmock.Conditional{})
+	req, err := http.NewRequest("GET", "http://z.tld/doc", nil)
+	require.CmpNoError(err)
+	resp, err = responder(req)
+	assert.CmpNoError(err)
+	assert.Nil(resp)
+
+	// Non-2xx responses are left untouched
+	responder = httpmock.NewStringResponder(404, "not found").
+		Conditional(httpmock.Conditional{ETag: "v1"})
+	resp = do("GET", map[string]string{"If-None-Match": `"v1"`})
+	assert.Cmp(resp.StatusCode, 404)
+	assert.Cmp(resp.Header.Get("ETag"), "")
+}
@@ -0,0 +1,269 @@
+package httpmock
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// CookieExists returns a [Matcher] checking that request contains a
+// name cookie.
+//
+// The name of the returned [Matcher] is auto-generated (see [NewMatcher]).
+// To name it explicitly, use [Matcher.WithName] as in:
+//
+//	CookieExists("session").WithName("10-has-session")
+func CookieExists(name string) Matcher {
+	return NewMatcher("",
+		func(req *http.Request) bool {
+			_, err := req.Cookie(name)
+			return err == nil
+		})
+}
+
+// CookieMissing returns a [Matcher] checking that request does not
+// contain any name cookie.
+//
+// The name of the returned [Matcher] is auto-generated (see [NewMatcher]).
+// To name it explicitly, use [Matcher.WithName] as in:
+//
+//	CookieMissing("session").WithName("10-no-session")
+func CookieMissing(name string) Matcher {
+	return NewMatcher("",
+		func(req *http.Request) bool {
+			_, err := req.Cookie(name)
+			return err != nil
+		})
+}
+
+// CookieIs returns a [Matcher] checking that request contains a name
+// cookie set to value. If several name cookies are sent, one of them
+// has to be set to value.
+//
+// The name of the returned [Matcher] is auto-generated (see [NewMatcher]).
+// To name it explicitly, use [Matcher.WithName] as in:
+//
+//	CookieIs("lang", "fr").WithName("10-lang-fr")
+func CookieIs(name, value string) Matcher {
+	return NewMatcher("",
+		func(req *http.Request) bool {
+			for _, c := range req.Cookies() {
+				if c.Name == name && c.Value == value {
+					return true
+				}
+			}
+			return false
+		})
+}
+
+// CookieMatches returns a [Matcher] checking that request contains a
+// name cookie whose value matches rx. If several name cookies are
+// sent, one of them has to match.
+//
+// The name of the returned [Matcher] is auto-generated (see [NewMatcher]).
+// To name it explicitly, use [Matcher.WithName] as in:
+//
+//	CookieMatches("id", regexp.MustCompile(`^[0-9a-f]{32}$`)).WithName("10-id-hex")
+func CookieMatches(name string, rx *regexp.Regexp) Matcher {
+	return NewMatcher("",
+		func(req *http.Request) bool {
+			for _, c := range req.Cookies() {
+				if c.Name == name && rx.MatchString(c.Value) {
+					return true
+				}
+			}
+			return false
+		})
+}
+
+// SetCookie returns a new [Responder] based on r that ensures the
+// returned [*http.Response] includes a Set-Cookie header for each of
+// cookies, with all their attributes (Path, Domain, Expires, MaxAge,
+// Secure, HttpOnly and SameSite).
+//
+// If the name of one of cookies is empty or not a valid token, a
+// panic occurs.
+//
+//	httpmock.RegisterResponder("GET", "/prefs",
+//	  httpmock.NewStringResponder(200, "OK").SetCookie(&http.Cookie{
+//	    Name:     "lang",
+//	    Value:    "fr",
+//	    Path:     "/",
+//	    MaxAge:   3600,
+//	    HttpOnly: true,
+//	  }))
+func (r Responder) SetCookie(cookies ...*http.Cookie) Responder {
+	values := make([]string, len(cookies))
+	for i, c := range cookies {
+		if !validCookieName(c.Name) {
+			panic(fmt.Sprintf("SetCookie bad cookie: invalid name %q", c.Name))
+		}
+		values[i] = c.String()
+	}
+	return r.HeaderAdd(http.Header{"Set-Cookie": values})
+}
+
+// validCookieName returns true if name is a non-empty token, as
+// defined in RFC 6265 section 4.1.1.
+func validCookieName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, c := range name {
+		if c <= ' ' || c >= 0x7f || strings.ContainsRune(`()<>@,;:\"/[]?={}`, c) {
+			return false
+		}
+	}
+	return true
+}
+
+// CookieSession simulates a cookie-based session, allowing to test
+// end to end a client using an [http.CookieJar]:
+//   - [CookieSession.Login] wraps the login route responder, so a new
+//     session cookie is set on success;
+//   - [CookieSession.Protect] wraps protected routes responders, so a
+//     401 Unauthorized response is returned without a valid session
+//     cookie;
+//   - [CookieSession.Logout] wraps the logout route responder, so the
+//     session is invalidated and its cookie cleared.
+//
+// For example:
+//
+//	session := httpmock.NewCookieSession(http.Cookie{Name: "sid", HttpOnly: true})
+//	httpmock.RegisterResponder("POST", "/login",
+//	  session.Login(httpmock.NewStringResponder(200, "welcome")))
+//	httpmock.RegisterResponder("GET", "/me",
+//	  session.Protect(httpmock.NewStringResponder(200, "bob")))
+//	httpmock.RegisterResponder("POST", "/logout",
+//	  session.Logout(httpmock.NewStringResponder(204, "")))
+type CookieSession struct {
+	cookie   http.Cookie
+	mu       sync.Mutex
+	sessions map[string]bool
+}
+
+// NewCookieSession returns a new [*CookieSession]. cookie is the
+// template of the session cookies: all its attributes are kept,
+// except Value that is set to a new random session ID at each login.
+// If cookie.Name is empty, "session" is used. If cookie.Path is
+// empty, "/" is used.
+func NewCookieSession(cookie http.Cookie) *CookieSession {
+	if cookie.Name == "" {
+		cookie.Name = "session"
+	}
+	if cookie.Path == "" {
+		cookie.Path = "/"
+	}
+	return &CookieSession{
+		cookie:   cookie,
+		sessions: map[string]bool{},
+	}
+}
+
+// sessionID returns the ID of the valid session of req, if any.
+func (s *CookieSession) sessionID(req *http.Request) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, c := range req.Cookies() {
+		if c.Name == s.cookie.Name && s.sessions[c.Value] {
+			return c.Value, true
+		}
+	}
+	return "", false
+}
+
+// addSetCookie returns a copy of resp with a Set-Cookie header for cookie.
+func addSetCookie(resp *http.Response, cookie *http.Cookie) (*http.Response, error) {
+	nr := *resp
+	if nr.Header == nil {
+		nr.Header = http.Header{}
+	}
+	nr.Header = nr.Header.Clone()
+	nr.Header.Add("Set-Cookie", cookie.String())
+	return &nr, nil
+}
+
+// Login returns a new [Responder] based on r that, if r returns a 2xx
+// response, creates a new session and sets its cookie in the response.
+func (s *CookieSession) Login(r Responder) Responder {
+	return func(req *http.Request) (*http.Response, error) {
+		resp, err := r(req)
+		if err != nil || resp == nil || resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return resp, err
+		}
+
+		cookie := s.cookie
+		cookie.Value = randomHex(16)
+
+		s.mu.Lock()
+		s.sessions[cookie.Value] = true
+		s.mu.Unlock()
+
+		return addSetCookie(resp, &cookie)
+	}
+}
+
+// Logout returns a new [Responder] based on r that invalidates the
+// session of the request, if any, then clears the session cookie in
+// the response of r.
+func (s *CookieSession) Logout(r Responder) Responder {
+	return func(req *http.Request) (*http.Response, error) {
+		if id, ok := s.sessionID(req); ok {
+			s.mu.Lock()
+			delete(s.sessions, id)
+			s.mu.Unlock()
+		}
+
+		resp, err := r(req)
+		if err != nil || resp == nil {
+			return resp, err
+		}
+		return addSetCookie(resp, &http.Cookie{
+			Name:   s.cookie.Name,
+			Path:   s.cookie.Path,
+			Domain: s.cookie.Domain,
+			MaxAge: -1,
+		})
+	}
+}
+
+// Protect returns a new [Responder] based on r that returns a 401
+// Unauthorized response if the request does not contain a valid
+// session cookie. Otherwise r is called.
+func (s *CookieSession) Protect(r Responder) Responder {
+	return func(req *http.Request) (*http.Response, error) {
+		if _, ok := s.sessionID(req); !ok {
+			resp := NewStringResponse(http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized))
+			resp.Request = req
+			return resp, nil
+		}
+		return r(req)
+	}
+}
+
+// Matcher returns a [Matcher] checking that request contains a valid
+// session cookie. It allows to register different responders for
+// logged-in and anonymous clients on the same route.
+//
+// The name of the returned [Matcher] is auto-generated (see [NewMatcher]).
+// To name it explicitly, use [Matcher.WithName] as in:
+//
+//	session.Matcher().WithName("10-logged-in")
+func (s *CookieSession) Matcher() Matcher {
+	return NewMatcher("",
+		func(req *http.Request) bool {
+			_, ok := s.sessionID(req)
+			return ok
+		})
+}
+
+// Active returns the number of active sessions, that is sessions
+// created by [CookieSession.Login] and not yet invalidated by
+// [CookieSession.Logout].
+func (s *CookieSession) Active() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.sessions)
+}
@@ -0,0 +1,165 @@
+package httpmock_test
+
+import (
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/td"
+
+	"github.com/jarcoal/httpmock"
+)
+
+func TestCookieMatchers(t *testing.T) {
+	assert, require := td.AssertRequire(t)
+
+	req, err := http.NewRequest("GET", "http://z.tld/", nil)
+	require.CmpNoError(err)
+	req.AddCookie(&http.Cookie{Name: "lang", Value: "fr"})
+	req.AddCookie(&http.Cookie{Name: "id", Value: "0a1b2c"})
+	req.AddCookie(&http.Cookie{Name: "id", Value: "zzz"})
+
+	assert.True(httpmock.CookieExists("lang").Check(req))
+	assert.False(httpmock.CookieExists("session").Check(req))
+	assert.True(httpmock.CookieMissing("session").Check(req))
+	assert.False(httpmock.CookieMissing("lang").Check(req))
+	assert.True(httpmock.CookieIs("lang", "fr").Check(req))
+	assert.False(httpmock.CookieIs("lang", "en").Check(req))
+	assert.True(httpmock.CookieIs("id", "zzz").Check(req))
+	assert.True(httpmock.CookieMatches("id", regexp.MustCompile(`^[0-9a-f]+$`)).Check(req))
+	assert.False(httpmock.CookieMatches("lang", regexp.MustCompile(`^en`)).Check(req))
+}
+
+func TestResponderSetCookie(t *testing.T) {
+	assert, require := td.AssertRequire(t)
+
+	req, err := http.NewRequest("GET", "http://z.tld/", nil)
+	require.CmpNoError(err)
+
+	resp, err := httpmock.NewStringResponder(200, "OK").
+		SetCookie(
+			&http.Cookie{
+				Name:     "lang",
+				Value:    "fr",
+				Path:     "/",
+				Domain:   "z.tld",
+				MaxAge:   3600,
+				Secure:   true,
+				HttpOnly: true,
+				SameSite: http.SameSiteStrictMode,
+			},
+			&http.Cookie{Name: "theme", Value: "dark"},
+		)(req)
+	require.CmpNoError(err)
+	assert.Cmp(resp.Header["Set-Cookie"], []string{
+		"lang=fr; Path=/; Domain=z.tld; Max-Age=3600; HttpOnly; Secure; SameSite=Strict",
+		"theme=dark",
+	})
+	assert.Cmp(resp.Cookies(), td.Len(2))
+
+	assert.CmpPanic(func() {
+		httpmock.NewStringResponder(200, "OK").SetCookie(&http.Cookie{Name: "a b"})
+	}, `SetCookie bad cookie: invalid name "a b"`)
+	assert.CmpPanic(func() {
+		httpmock.NewStringResponder(200, "OK").SetCookie(&http.Cookie{Value: "x"})
+	}, `SetCookie bad cookie: invalid name ""`)
+}
+
+func TestCookieSessionNilResponse(t *testing.T) {
+	assert, require := td.AssertRequire(t)
+
+	nilResponder := func(*http.Request) (*http.Response, error) { return nil, nil }
+	session := httpmock.NewCookieSession(http.Cookie{Name: "sid"})
+
+	req, err := http.NewRequest("POST", "http://z.tld/login", nil)
+	require.CmpNoError(err)
+	for _, r := range []httpmock.Responder{session.Login(nilResponder), session.Logout(nilResponder)} {
+		resp, err := r(req)
+		assert.CmpNoError(err)
+		assert.Nil(resp)
+	}
+}
+
+func TestCookieSession(t *testing.T) {
+	assert, require := td.AssertRequire(t)
+
+	mt := httpmock.NewMockTransport()
+	jar, err := cookiejar.New(nil)
+	require.CmpNoError(err)
+	client := &http.Client{Transport: mt, Jar: jar}
+
+	session := httpmock.NewCookieSession(http.Cookie{Name: "sid", HttpOnly: true})
+
+	mt.RegisterMatcherResponder("POST", "http://z.tld/login",
+		httpmock.BodyContainsString("good"),
+		session.Login(httpmock.NewStringResponder(200, "welcome")))
+	mt.RegisterResponder("POST", "http://z.tld/login",
+		session.Login(httpmock.NewStringResponder(403, "bad password")))
+	mt.RegisterResponder("GET", "http://z.tld/me",
+		session.Protect(httpmock.NewStringResponder(200, "bob")))
+	mt.RegisterMatcherResponder("GET", "http://z.tld/home",
+		session.Matcher(),
+		httpmock.NewStringResponder(200, "hello bob"))
+	mt.RegisterResponder("GET", "http://z.tld/home",
+		httpmock.NewStringResponder(200, "hello stranger"))
+	mt.RegisterResponder("POST", "http://z.tld/logout",
+		session.Logout(httpmock.NewStringResponder(204, "")))
+
+	get := func(url string) *http.Response {
+		resp, err := client.Get(url)
+		require.CmpNoError(err)
+		return resp
+	}
+	post := func(url, body string) *http.Response {
+		resp, err := client.Post(url, "text/plain", strings.NewReader(body))
+		require.CmpNoError(err)
+		return resp
+	}
+
+	assert.Cmp(get("http://z.tld/me").StatusCode, http.StatusUnauthorized)
+	assertBody(assert, get("http://z.tld/home"), "hello stranger")
+
+	// Failed login
+	resp := post("http://z.tld/login", "bad")
+	assert.Cmp(resp.StatusCode, http.StatusForbidden)
+	assert.Cmp(resp.Header["Set-Cookie"], td.Empty())
+	assert.Cmp(session.Active(), 0)
+
+	// Successful login
+	resp = post("http://z.tld/login", "good")
+	assert.Cmp(resp.StatusCode, http.StatusOK)
+	assert.Cmp(resp.Cookies(), td.Bag(td.Struct(&http.Cookie{
+		Name:     "sid",
+		Path:     "/",
+		HttpOnly: true,
+	}, td.StructFields{"Value": td.Re(`^[0-9a-f]{32}\z`)})))
+	assert.Cmp(session.Active(), 1)
+
+	u, _ := url.Parse("http://z.tld/")
+	assert.Cmp(jar.Cookies(u), td.Len(1))
+
+	resp = get("http://z.tld/me")
+	assert.Cmp(resp.StatusCode, http.StatusOK)
+	assertBody(assert, resp, "bob")
+	assertBody(assert, get("http://z.tld/home"), "hello bob")
+
+	// Logout
+	resp = post("http://z.tld/logout", "")
+	assert.Cmp(resp.StatusCode, http.StatusNoContent)
+	assert.Cmp(resp.Header.Get("Set-Cookie"), "sid=; Path=/; Max-Age=0")
+	assert.Cmp(session.Active(), 0)
+	assert.Cmp(jar.Cookies(u), td.Empty())
+
+	assert.Cmp(get("http://z.tld/me").StatusCode, http.StatusUnauthorized)
+
+	// Replaying an invalidated session cookie does not work
+	req, err := http.NewRequest("GET", "http://z.tld/me", nil)
+	require.CmpNoError(err)
+	req.AddCookie(&http.Cookie{Name: "sid", Value: "0123456789abcdef0123456789abcdef"})
+	resp, err = mt.RoundTrip(req)
+	require.CmpNoError(err)
+	assert.Cmp(resp.StatusCode, http.StatusUnauthorized)
+}
@@ -81,3 +81,9 @@ func (mrs matchResponders) FindMatchResponder(req *http.Request) *matchResponder
 func (m Matcher) FnPointer() uintptr {
 	return reflect.ValueOf(m.fn).Pointer()
 }
+
+const MaxMatcherDistance = maxMatcherDistance
+
+func (m Matcher) Diff(req *http.Request) (int, string) {
+	return m.diff(req)
+}
@@ -0,0 +1,210 @@
+package httpmock
+
+import (
+	"errors"
+	"io"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrFaultInjected is the error returned by a [FaultError] [Fault]
+// when its Err field is nil.
+var ErrFaultInjected = errors.New("fault injected")
+
+// FaultKind is the kind of a [Fault].
+type FaultKind int
+
+const (
+	// FaultError makes the [Responder] return Fault.Err (or
+	// [ErrFaultInjected] if nil) instead of a response.
+	FaultError FaultKind = iota
+	// FaultStatus makes the [Responder] return a response with status
+	// Fault.Status (or 503 if 0) instead of calling the original
+	// [Responder].
+	FaultStatus
+	// FaultTruncate makes the body of the original response
+	// return [io.ErrUnexpectedEOF] after Fault.TruncateAt bytes.
+	FaultTruncate
+	// FaultLatency delays the call of the original [Responder] by
+	// Fault.Latency.
+	FaultLatency
+)
+
+// Fault describes a fault injected by [Responder.WithFaults] or
+// [MockTransport.SetFaults].
+type Fault struct {
+	Kind FaultKind
+	// Probability, between 0 and 1, is the probability this fault
+	// occurs for each call.
+	Probability float64
+	// Calls lists the call numbers (starting at 1) on which this
+	// fault always occurs, whatever Probability is.
+	Calls []int
+	// Err is the error returned by FaultError. Defaults to
+	// [ErrFaultInjected].
+	Err error
+	// Status is the status code returned by FaultStatus. Defaults to
+	// 503.
+	Status int
+	// TruncateAt is the number of body bytes delivered by
+	// FaultTruncate before failing.
+	TruncateAt int
+	// Latency is the duration added by FaultLatency. It is measured
+	// using the [Clock] of the [MockTransport] (see
+	// [MockTransport.SetClock]).
+	Latency time.Duration
+}
+
+func (f *Fault) occurs(call int, rng *rand.Rand) bool {
+	// always draw, so the random sequence only depends on the seed
+	// and the number of calls
+	hit := rng.Float64() < f.Probability
+	for _, c := range f.Calls {
+		if c == call {
+			return true
+		}
+	}
+	return hit
+}
+
+type faultInjector struct {
+	mu     sync.Mutex
+	rng    *rand.Rand
+	calls  int
+	faults []Fault
+}
+
+func newFaultInjector(seed int64, faults []Fault) *faultInjector {
+	return &faultInjector{
+		rng:    rand.New(rand.NewSource(seed)), //nolint: gosec
+		faults: faults,
+	}
+}
+
+// draw returns the total latency to add and the first non-latency
+// fault occurring for the current call, if any.
+func (fi *faultInjector) draw() (latency time.Duration, fault *Fault) {
+	fi.mu.Lock()
+	defer fi.mu.Unlock()
+
+	fi.calls++
+	for i := range fi.faults {
+		f := &fi.faults[i]
+		if !f.occurs(fi.calls, fi.rng) {
+			continue
+		}
+		if f.Kind == FaultLatency {
+			latency += f.Latency
+		} else if fault == nil {
+			fault = f
+		}
+	}
+	return
+}
+
+func (fi *faultInjector) wrap(r Responder) Responder {
+	return func(req *http.Request) (*http.Response, error) {
+		latency, fault := fi.draw()
+
+		if latency > 0 {
+			if err := sleep(req, clockOf(req), latency); err != nil {
+				return nil, err
+			}
+		}
+
+		if fault != nil {
+			switch fault.Kind {
+			case FaultError:
+				if fault.Err != nil {
+					return nil, fault.Err
+				}
+				return nil, ErrFaultInjected
+
+			case FaultStatus:
+				status := fault.Status
+				if status == 0 {
+					status = http.StatusServiceUnavailable
+				}
+				resp := NewStringResponse(status, http.StatusText(status))
+				resp.Request = req
+				return resp, nil
+			}
+		}
+
+		resp, err := r(req)
+		if err != nil || fault == nil || resp == nil {
+			return resp, err
+		}
+
+		// FaultTruncate
+		nr := *resp
+		body := nr.Body
+		if body == nil {
+			body = http.NoBody
+		}
+		nr.Body = &truncatedBody{body: body, remain: fault.TruncateAt, err: io.ErrUnexpectedEOF}
+		return &nr, nil
+	}
+}
+
+// truncatedBody returns err once remain bytes of body have been
+// read, or when body ends before.
+type truncatedBody struct {
+	body   io.ReadCloser
+	remain int
+	err    error
+}
+
+func (b *truncatedBody) Read(p []byte) (int, error) {
+	if b.remain <= 0 {
+		return 0, b.err
+	}
+	if len(p) > b.remain {
+		p = p[:b.remain]
+	}
+	n, err := b.body.Read(p)
+	b.remain -= n
+	if err == io.EOF {
+		err = b.err
+	}
+	return n, err
+}
+
+func (b *truncatedBody) Close() error {
+	return b.body.Close()
+}
+
+// WithFaults returns a new [Responder] that injects faults when
+// calling the original r Responder. Each call, each fault occurs
+// depending on its Probability and Calls fields (see [Fault]). The
+// pseudo-random sequence is generated using seed, so that two runs
+// using the same seed inject the same faults at the same calls.
+//
+// If several [FaultLatency] faults occur for the same call, their
+// latencies are added. Then the first other occurring fault, in
+// faults order, is applied.
+//
+//	import (
+//	  "testing"
+//	  "time"
+//	  "github.com/jarcoal/httpmock"
+//	)
+//	...
+//	func TestMyApp(t *testing.T) {
+//	  ...
+//	  httpmock.RegisterResponder("GET", "/foo/bar",
+//	    httpmock.NewStringResponder(200, "{}").WithFaults(42,
+//	      // 10% of calls are slow
+//	      httpmock.Fault{Kind: httpmock.FaultLatency, Probability: 0.1, Latency: time.Second},
+//	      // 2nd call fails, then 20% of calls fail
+//	      httpmock.Fault{Kind: httpmock.FaultStatus, Probability: 0.2, Calls: []int{2}},
+//	    ),
+//	  )
+//
+// See also [MockTransport.SetFaults] to inject faults for all
+// responders of a [MockTransport].
+func (r Responder) WithFaults(seed int64, faults ...Fault) Responder {
+	return newFaultInjector(seed, faults).wrap(r)
+}
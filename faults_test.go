@@ -0,0 +1,121 @@
+package httpmock_test
+
+import (
+	"errors"
+	"io"
+	"io/ioutil" //nolint: staticcheck
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+
+	"github.com/jarcoal/httpmock"
+)
+
+func TestResponderWithFaults(t *testing.T) {
+	assert := td.Assert(t)
+
+	call := func(assert *td.T, r httpmock.Responder) (*http.Response, error) {
+		assert.Helper()
+		req, err := http.NewRequest(http.MethodGet, testURL, nil)
+		td.Require(assert).CmpNoError(err)
+		return r(req)
+	}
+
+	assert.Run("calls", func(assert *td.T) {
+		myErr := errors.New("boom")
+		r := httpmock.NewStringResponder(200, "Hello World!").WithFaults(0,
+			httpmock.Fault{Kind: httpmock.FaultError, Calls: []int{1}, Err: myErr},
+			httpmock.Fault{Kind: httpmock.FaultError, Calls: []int{2}},
+			httpmock.Fault{Kind: httpmock.FaultStatus, Calls: []int{3}},
+			httpmock.Fault{Kind: httpmock.FaultStatus, Calls: []int{4}, Status: 500},
+			httpmock.Fault{Kind: httpmock.FaultTruncate, Calls: []int{5}, TruncateAt: 5},
+			httpmock.Fault{Kind: httpmock.FaultLatency, Calls: []int{6}, Latency: 10 * time.Millisecond},
+		)
+
+		_, err := call(assert, r)
+		assert.Cmp(err, myErr)
+
+		_, err = call(assert, r)
+		assert.Cmp(err, httpmock.ErrFaultInjected)
+
+		resp, err := call(assert, r)
+		assert.CmpNoError(err)
+		assert.Cmp(resp.StatusCode, 503)
+		assertBody(assert, resp, "Service Unavailable")
+
+		resp, err = call(assert, r)
+		assert.CmpNoError(err)
+		assert.Cmp(resp.StatusCode, 500)
+
+		resp, err = call(assert, r)
+		assert.CmpNoError(err)
+		body, err := ioutil.ReadAll(resp.Body)
+		assert.Cmp(err, io.ErrUnexpectedEOF)
+		assert.Cmp(string(body), "Hello")
+
+		before := time.Now()
+		resp, err = call(assert, r)
+		assert.CmpNoError(err)
+		assert.Gte(time.Since(before), 10*time.Millisecond)
+		assertBody(assert, resp, "Hello World!")
+	})
+
+	assert.Run("seed", func(assert *td.T) {
+		run := func(seed int64) (res []bool) {
+			r := httpmock.NewStringResponder(200, "OK").WithFaults(seed,
+				httpmock.Fault{Kind: httpmock.FaultError, Probability: 0.5})
+			for i := 0; i < 64; i++ {
+				_, err := call(assert, r)
+				res = append(res, err != nil)
+			}
+			return
+		}
+
+		first := run(42)
+		assert.Cmp(first, td.Contains(true))
+		assert.Cmp(first, td.Contains(false))
+		assert.Cmp(run(42), first)
+		assert.Not(run(43), first)
+	})
+}
+
+func TestMockTransportSetFaults(t *testing.T) {
+	assert, require := td.AssertRequire(t)
+
+	mt := httpmock.NewMockTransport()
+	mt.RegisterResponder("GET", "/foo", httpmock.NewStringResponder(200, "foo"))
+	mt.RegisterResponder("GET", "/bar", httpmock.NewStringResponder(200, "bar"))
+
+	mt.SetFaults(0, httpmock.Fault{Kind: httpmock.FaultStatus, Calls: []int{2}})
+
+	client := &http.Client{Transport: mt}
+
+	resp, err := client.Get("http://z.tld/foo")
+	require.CmpNoError(err)
+	assert.Cmp(resp.StatusCode, 200)
+
+	resp, err = client.Get("http://z.tld/bar")
+	require.CmpNoError(err)
+	assert.Cmp(resp.StatusCode, 503)
+
+	resp, err = client.Get("http://z.tld/bar")
+	require.CmpNoError(err)
+	assert.Cmp(resp.StatusCode, 200)
+
+	// Disable
+	mt.SetFaults(0, httpmock.Fault{Kind: httpmock.FaultError, Probability: 1})
+	_, err = client.Get("http://z.tld/foo")
+	assert.CmpError(err)
+	mt.SetFaults(0)
+	_, err = client.Get("http://z.tld/foo")
+	assert.CmpNoError(err)
+
+	// Reset disables too
+	mt.SetFaults(0, httpmock.Fault{Kind: httpmock.FaultError, Probability: 1})
+	mt.Reset()
+	mt.RegisterResponder("GET", "/foo", httpmock.NewStringResponder(200, "foo"))
+	_, err = client.Get("http://z.tld/foo")
+	assert.CmpNoError(err)
+}
@@ -0,0 +1,207 @@
+package httpmock
+
+import (
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+)
+
+// formValues returns the fields of the application/x-www-form-urlencoded
+// body of req. false is returned if req has another Content-Type or if
+// its body cannot be parsed. The body is restored, so it can be read
+// again later.
+func formValues(req *http.Request) (url.Values, bool) {
+	if ct := req.Header.Get("Content-Type"); ct != "" {
+		mediaType, _, err := mime.ParseMediaType(ct)
+		if err != nil || mediaType != "application/x-www-form-urlencoded" {
+			return nil, false
+		}
+	}
+	body, err := readDecodedBody(req)
+	if err != nil {
+		return nil, false
+	}
+	values, err := url.ParseQuery(string(body))
+	return values, err == nil
+}
+
+// queryOf returns the query string fields of req.
+func queryOf(req *http.Request) (url.Values, bool) {
+	values, err := url.ParseQuery(req.URL.RawQuery)
+	return values, err == nil
+}
+
+// valuesIs returns a [MatcherFunc] checking that the values returned
+// by get are exactly expected, whatever the keys and values order.
+func valuesIs(get func(*http.Request) (url.Values, bool), expected url.Values) MatcherFunc {
+	sortedExpected := sortedQuery(expected)
+	return func(req *http.Request) bool {
+		got, ok := get(req)
+		return ok && sortedQuery(got) == sortedExpected
+	}
+}
+
+// valuesContains returns a [MatcherFunc] checking that the values
+// returned by get contain all the keys of expected, each one with
+// exactly the same values, whatever their order. Other keys are
+// ignored.
+func valuesContains(get func(*http.Request) (url.Values, bool), expected url.Values) MatcherFunc {
+	sortedExpected := sortedQuery(expected)
+	return func(req *http.Request) bool {
+		got, ok := get(req)
+		if !ok {
+			return false
+		}
+		sub := make(url.Values, len(expected))
+		for key := range expected {
+			if values, ok := got[key]; ok {
+				sub[key] = values
+			}
+		}
+		return sortedQuery(sub) == sortedExpected
+	}
+}
+
+// valuesMatch returns a [MatcherFunc] checking that at least one
+// value of key in the values returned by get matches rx.
+func valuesMatch(get func(*http.Request) (url.Values, bool), key string, rx *regexp.Regexp) MatcherFunc {
+	return func(req *http.Request) bool {
+		got, ok := get(req)
+		if !ok {
+			return false
+		}
+		for _, v := range got[key] {
+			if rx.MatchString(v) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// valuesMissing returns a [MatcherFunc] checking that key is not
+// present in the values returned by get.
+func valuesMissing(get func(*http.Request) (url.Values, bool), key string) MatcherFunc {
+	return func(req *http.Request) bool {
+		got, ok := get(req)
+		if !ok {
+			return false
+		}
+		_, ok = got[key]
+		return !ok
+	}
+}
+
+// FormIs returns a [Matcher] checking that request body is an
+// application/x-www-form-urlencoded form containing exactly the
+// fields of form, whatever the fields and values order. As for
+// [MockTransport.RegisterResponderWithQuery], form type can be:
+//
+//   - [url.Values]
+//   - map[string]string
+//   - string, a query string like "a=12&a=13&b=z&c" (see [url.ParseQuery] function)
+//
+// If the form type is not recognized or the string cannot be parsed
+// using [url.ParseQuery], a panic() occurs.
+//
+// If the request has a Content-Type header, it must be
+// application/x-www-form-urlencoded for the matcher to succeed.
+//
+// The name of the returned [Matcher] is auto-generated (see [NewMatcher]).
+// To name it explicitly, use [Matcher.WithName] as in:
+//
+//	FormIs("user=bob&age=42").WithName("10-form-is-bob")
+func FormIs(form any) Matcher {
+	return NewMatcher("", valuesIs(formValues, queryValues("FormIs", form)))
+}
+
+// FormContains returns a [Matcher] checking that request body is an
+// application/x-www-form-urlencoded form containing at least the
+// fields of form. Each field of form must be present with exactly
+// the same values, whatever their order. Other fields are ignored.
+// See [FormIs] for form accepted types.
+//
+// The name of the returned [Matcher] is auto-generated (see [NewMatcher]).
+// To name it explicitly, use [Matcher.WithName] as in:
+//
+//	FormContains(map[string]string{"user": "bob"}).WithName("10-form-user-bob")
+func FormContains(form any) Matcher {
+	return NewMatcher("", valuesContains(formValues, queryValues("FormContains", form)))
+}
+
+// FormFieldMatches returns a [Matcher] checking that request body is
+// an application/x-www-form-urlencoded form containing a key field
+// with at least one value matching rx.
+//
+// The name of the returned [Matcher] is auto-generated (see [NewMatcher]).
+// To name it explicitly, use [Matcher.WithName] as in:
+//
+//	FormFieldMatches("age", regexp.MustCompile(`^\d+$`)).WithName("10-age-is-num")
+func FormFieldMatches(key string, rx *regexp.Regexp) Matcher {
+	return NewMatcher("", valuesMatch(formValues, key, rx))
+}
+
+// FormFieldMissing returns a [Matcher] checking that request body is
+// an application/x-www-form-urlencoded form not containing the key
+// field.
+//
+// The name of the returned [Matcher] is auto-generated (see [NewMatcher]).
+// To name it explicitly, use [Matcher.WithName] as in:
+//
+//	FormFieldMissing("password").WithName("10-no-password")
+func FormFieldMissing(key string) Matcher {
+	return NewMatcher("", valuesMissing(formValues, key))
+}
+
+// QueryIs returns a [Matcher] checking that request URL query string
+// contains exactly the fields of query, whatever the fields and
+// values order. See [FormIs] for query accepted types.
+//
+// Unlike [MockTransport.RegisterResponderWithQuery], it can be used
+// with any route, including regexp ones.
+//
+// The name of the returned [Matcher] is auto-generated (see [NewMatcher]).
+// To name it explicitly, use [Matcher.WithName] as in:
+//
+//	QueryIs("page=2&sort=name").WithName("10-page-2")
+func QueryIs(query any) Matcher {
+	return NewMatcher("", valuesIs(queryOf, queryValues("QueryIs", query)))
+}
+
+// QueryContains returns a [Matcher] checking that request URL query
+// string contains at least the fields of query. Each field of query
+// must be present with exactly the same values, whatever their
+// order. Other fields are ignored. See [FormIs] for query accepted
+// types.
+//
+// The name of the returned [Matcher] is auto-generated (see [NewMatcher]).
+// To name it explicitly, use [Matcher.WithName] as in:
+//
+//	QueryContains(map[string]string{"page": "2"}).WithName("10-page-2")
+func QueryContains(query any) Matcher {
+	return NewMatcher("", valuesContains(queryOf, queryValues("QueryContains", query)))
+}
+
+// QueryFieldMatches returns a [Matcher] checking that request URL
+// query string contains a key field with at least one value matching
+// rx.
+//
+// The name of the returned [Matcher] is auto-generated (see [NewMatcher]).
+// To name it explicitly, use [Matcher.WithName] as in:
+//
+//	QueryFieldMatches("page", regexp.MustCompile(`^\d+$`)).WithName("10-page-is-num")
+func QueryFieldMatches(key string, rx *regexp.Regexp) Matcher {
+	return NewMatcher("", valuesMatch(queryOf, key, rx))
+}
+
+// QueryFieldMissing returns a [Matcher] checking that request URL
+// query string does not contain the key field.
+//
+// The name of the returned [Matcher] is auto-generated (see [NewMatcher]).
+// To name it explicitly, use [Matcher.WithName] as in:
+//
+//	QueryFieldMissing("debug").WithName("10-no-debug")
+func QueryFieldMissing(key string) Matcher {
+	return NewMatcher("", valuesMissing(queryOf, key))
+}
@@ -0,0 +1,113 @@
+package httpmock_test
+
+import (
+	"io/ioutil" //nolint: staticcheck
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/td"
+
+	"github.com/jarcoal/httpmock"
+)
+
+func TestFormMatchers(t *testing.T) {
+	assert := td.Assert(t)
+
+	newReq := func(contentType, body string) *http.Request {
+		req, err := http.NewRequest("POST", "http://z.tld/form", strings.NewReader(body))
+		td.Require(t).CmpNoError(err)
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		return req
+	}
+	const form = "application/x-www-form-urlencoded"
+
+	for _, tc := range []struct {
+		name     string
+		matcher  httpmock.Matcher
+		ctype    string
+		body     string
+		expected bool
+	}{
+		{"is", httpmock.FormIs("a=1&b=2&b=3"), form, "b=3&a=1&b=2", true},
+		{"is+charset", httpmock.FormIs("a=1"), form + "; charset=utf-8", "a=1", true},
+		{"is+no-ctype", httpmock.FormIs(url.Values{"a": {"1"}}), "", "a=1", true},
+		{"is+bad-ctype", httpmock.FormIs("a=1"), "text/plain", "a=1", false},
+		{"is+more", httpmock.FormIs("a=1"), form, "a=1&b=2", false},
+		{"is+less-values", httpmock.FormIs("a=1&a=2"), form, "a=1", false},
+		{"is+bad-body", httpmock.FormIs("a=1"), form, "a=%zz", false},
+		{"contains", httpmock.FormContains(map[string]string{"a": "1"}), form, "b=2&a=1", true},
+		{"contains+values", httpmock.FormContains("a=2&a=1"), form, "a=1&b=2&a=2", true},
+		{"contains+missing", httpmock.FormContains("a=1&c=3"), form, "a=1&b=2", false},
+		{"contains+other-value", httpmock.FormContains("a=1"), form, "a=1&a=2", false},
+		{"matches", httpmock.FormFieldMatches("a", regexp.MustCompile(`^\d+$`)), form, "a=x&a=12", true},
+		{"matches+no", httpmock.FormFieldMatches("a", regexp.MustCompile(`^\d+$`)), form, "a=x", false},
+		{"matches+missing", httpmock.FormFieldMatches("a", regexp.MustCompile(`.*`)), form, "b=1", false},
+		{"missing", httpmock.FormFieldMissing("pass"), form, "user=bob", true},
+		{"missing+present", httpmock.FormFieldMissing("pass"), form, "user=bob&pass=", false},
+		{"missing+bad-ctype", httpmock.FormFieldMissing("pass"), "application/json", "{}", false},
+	} {
+		assert.Run(tc.name, func(assert *td.T) {
+			req := newReq(tc.ctype, tc.body)
+			assert.Cmp(tc.matcher.Check(req), tc.expected)
+			// body is still readable
+			b, err := ioutil.ReadAll(req.Body)
+			assert.CmpNoError(err)
+			assert.Cmp(string(b), tc.body)
+		})
+	}
+
+	assert.CmpPanic(func() { httpmock.FormIs("a=%zz") },
+		td.HasPrefix("FormIs bad query string: "))
+	assert.CmpPanic(func() { httpmock.FormContains(12) },
+		"FormContains bad query type int. Only url.Values, map[string]string and string are allowed")
+}
+
+func TestQueryMatchers(t *testing.T) {
+	assert, require := td.AssertRequire(t)
+
+	for _, tc := range []struct {
+		name     string
+		matcher  httpmock.Matcher
+		query    string
+		expected bool
+	}{
+		{"is", httpmock.QueryIs("a=1&b=2&b=3"), "b=3&a=1&b=2", true},
+		{"is+empty", httpmock.QueryIs(nil), "", true},
+		{"is+more", httpmock.QueryIs("a=1"), "a=1&b=2", false},
+		{"contains", httpmock.QueryContains(map[string]string{"a": "1"}), "b=2&a=1", true},
+		{"contains+missing", httpmock.QueryContains("c=3"), "a=1", false},
+		{"matches", httpmock.QueryFieldMatches("page", regexp.MustCompile(`^\d+$`)), "page=2", true},
+		{"matches+no", httpmock.QueryFieldMatches("page", regexp.MustCompile(`^\d+$`)), "page=x", false},
+		{"missing", httpmock.QueryFieldMissing("debug"), "page=2", true},
+		{"missing+present", httpmock.QueryFieldMissing("debug"), "debug", false},
+		{"bad", httpmock.QueryFieldMissing("debug"), "a=%zz", false},
+	} {
+		assert.Run(tc.name, func(assert *td.T) {
+			req, err := http.NewRequest("GET", "http://z.tld/q?"+tc.query, nil)
+			require.CmpNoError(err)
+			assert.Cmp(tc.matcher.Check(req), tc.expected)
+		})
+	}
+
+	// Used with a regexp route
+	mt := httpmock.NewMockTransport()
+	mt.RegisterRegexpMatcherResponder("GET", regexp.MustCompile(`/items/\d+`),
+		httpmock.QueryContains("full=1"),
+		httpmock.NewStringResponder(200, "full"))
+	mt.RegisterRegexpResponder("GET", regexp.MustCompile(`/items/\d+`),
+		httpmock.NewStringResponder(200, "short"))
+
+	client := &http.Client{Transport: mt}
+	resp, err := client.Get("http://z.tld/items/12?lang=fr&full=1")
+	require.CmpNoError(err)
+	assertBody(assert, resp, "full")
+
+	resp, err = client.Get("http://z.tld/items/12?lang=fr")
+	require.CmpNoError(err)
+	assertBody(assert, resp, "short")
+}
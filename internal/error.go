@@ -3,6 +3,7 @@ package internal
 import (
 	"errors"
 	"fmt"
+	"strings"
 )
 
 // NoResponderFound is returned when no responders are found for a
@@ -15,6 +16,8 @@ type ErrorNoResponderFoundMistake struct {
 	Kind      string // "method", "URL" or "matcher"
 	Orig      string // original wrong method/URL, without any matching responder
 	Suggested string // suggested method/URL with a matching responder
+	Closest   string // for "matcher" kind, name of the closest matcher if any
+	Diff      string // for "matcher" kind, differences with the closest matcher
 }
 
 var _ error = (*ErrorNoResponderFoundMistake)(nil)
@@ -27,9 +30,10 @@ func (e *ErrorNoResponderFoundMistake) Unwrap() error {
 // Error implements error interface.
 func (e *ErrorNoResponderFoundMistake) Error() string {
 	if e.Kind == "matcher" {
-		return fmt.Sprintf("%s despite %s",
+		return fmt.Sprintf("%s despite %s%s",
 			NoResponderFound,
 			e.Suggested,
+			ClosestMatcher(e.Closest, e.Diff),
 		)
 	}
 	return fmt.Sprintf("%[1]s for %[2]s %[3]q, but one matches %[2]s %[4]q",
@@ -39,3 +43,14 @@ func (e *ErrorNoResponderFoundMistake) Error() string {
 		e.Suggested,
 	)
 }
+
+// ClosestMatcher returns the explanation of why the closest matcher
+// name did not match, given its differences diff, one per line. It
+// returns "" if name is empty.
+func ClosestMatcher(name, diff string) string {
+	if name == "" {
+		return ""
+	}
+	return fmt.Sprintf(", closest matcher %q differs:\n\t%s",
+		name, strings.ReplaceAll(diff, "\n", "\n\t"))
+}
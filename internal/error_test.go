@@ -24,4 +24,8 @@ func TestErrorNoResponderFoundMistake(t *testing.T) {
 	}
 	td.Cmp(t, e.Error(), `no responder found despite BINGO`)
 	td.Cmp(t, e.Unwrap(), internal.NoResponderFound)
+
+	e.Closest = "00-json"
+	e.Diff = "$.a: missing\n$.b: unexpected"
+	td.Cmp(t, e.Error(), "no responder found despite BINGO, closest matcher \"00-json\" differs:\n\t$.a: missing\n\t$.b: unexpected")
 }
@@ -0,0 +1,452 @@
+package httpmock
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// jsonIgnored replaces array items ignored by [JSONBodyIs] and
+// [JSONBodyContains], so they are always considered equal.
+type jsonIgnored struct{}
+
+// jsonPathElem is one element of a JSON path, see [parseJSONPath].
+type jsonPathElem struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+var jsonIdentRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// parseJSONPath parses a JSON path like "$.items[0].id",
+// "items[*].tags" or `$["odd key"].*`. The leading "$" is optional. A
+// panic occurs if path cannot be parsed, prefixed by caller.
+func parseJSONPath(caller, path string) []jsonPathElem {
+	bad := func() {
+		panic(fmt.Sprintf("%s bad JSON path %q", caller, path))
+	}
+
+	var elems []jsonPathElem
+	p := strings.TrimPrefix(path, "$")
+	first := len(p) == len(path)
+	for p != "" {
+		switch {
+		case p[0] == '[':
+			end := strings.IndexByte(p, ']')
+			if end < 0 {
+				bad()
+			}
+			inner := p[1:end]
+			p = p[end+1:]
+			switch {
+			case inner == "*":
+				elems = append(elems, jsonPathElem{isIndex: true, wildcard: true})
+			case len(inner) >= 2 && (inner[0] == '"' || inner[0] == '\''):
+				key := inner[1 : len(inner)-1]
+				if inner[len(inner)-1] != inner[0] {
+					bad()
+				}
+				if inner[0] == '"' {
+					var err error
+					if key, err = strconv.Unquote(inner); err != nil {
+						bad()
+					}
+				}
+				elems = append(elems, jsonPathElem{key: key})
+			default:
+				index, err := strconv.Atoi(inner)
+				if err != nil || index < 0 {
+					bad()
+				}
+				elems = append(elems, jsonPathElem{index: index, isIndex: true})
+			}
+
+		case p[0] == '.' || first:
+			if !first {
+				p = p[1:]
+			}
+			end := strings.IndexAny(p, ".[")
+			if end < 0 {
+				end = len(p)
+			}
+			if end == 0 {
+				bad()
+			}
+			if key := p[:end]; key == "*" {
+				elems = append(elems, jsonPathElem{wildcard: true})
+			} else {
+				elems = append(elems, jsonPathElem{key: key})
+			}
+			p = p[end:]
+
+		default:
+			bad()
+		}
+		first = false
+	}
+	return elems
+}
+
+func jsonPathKey(path, key string) string {
+	if jsonIdentRe.MatchString(key) {
+		return path + "." + key
+	}
+	return path + "[" + strconv.Quote(key) + "]"
+}
+
+func jsonPathIndex(path string, index int) string {
+	return path + "[" + strconv.Itoa(index) + "]"
+}
+
+func sortedJSONKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// jsonSelect calls fn for each value of v targeted by path, with its
+// string path.
+func jsonSelect(v any, path []jsonPathElem, strPath string, fn func(string, any)) {
+	if len(path) == 0 {
+		fn(strPath, v)
+		return
+	}
+	elem := path[0]
+	switch tv := v.(type) {
+	case map[string]any:
+		if elem.isIndex {
+			return
+		}
+		if elem.wildcard {
+			for _, k := range sortedJSONKeys(tv) {
+				jsonSelect(tv[k], path[1:], jsonPathKey(strPath, k), fn)
+			}
+		} else if sub, ok := tv[elem.key]; ok {
+			jsonSelect(sub, path[1:], jsonPathKey(strPath, elem.key), fn)
+		}
+
+	case []any:
+		if !elem.isIndex && !elem.wildcard {
+			return
+		}
+		if elem.wildcard {
+			for i, sub := range tv {
+				jsonSelect(sub, path[1:], jsonPathIndex(strPath, i), fn)
+			}
+		} else if elem.index < len(tv) {
+			jsonSelect(tv[elem.index], path[1:], jsonPathIndex(strPath, elem.index), fn)
+		}
+	}
+}
+
+// jsonIgnore removes from v the values targeted by path. Object
+// entries are deleted, array items are replaced by jsonIgnored{}.
+func jsonIgnore(v any, path []jsonPathElem) {
+	if len(path) == 0 {
+		return
+	}
+	elem, last := path[0], len(path) == 1
+	switch tv := v.(type) {
+	case map[string]any:
+		if elem.isIndex {
+			return
+		}
+		for k, sub := range tv {
+			if elem.wildcard || k == elem.key {
+				if last {
+					delete(tv, k)
+				} else {
+					jsonIgnore(sub, path[1:])
+				}
+			}
+		}
+
+	case []any:
+		if !elem.isIndex && !elem.wildcard {
+			return
+		}
+		for i, sub := range tv {
+			if elem.wildcard || i == elem.index {
+				if last {
+					tv[i] = jsonIgnored{}
+				} else {
+					jsonIgnore(sub, path[1:])
+				}
+			}
+		}
+	}
+}
+
+func jsonString(v any) string {
+	if _, ok := v.(jsonIgnored); ok {
+		return "<ignored>"
+	}
+	b, _ := json.Marshal(v) // cannot fail as v comes from a JSON decoding
+	return string(b)
+}
+
+// jsonDiff appends to diffs the differences between got and
+// expected. If subset is true, got objects can contain keys not
+// present in expected ones.
+func jsonDiff(diffs []string, path string, got, expected any, subset bool) []string {
+	if _, ok := got.(jsonIgnored); ok {
+		return diffs
+	}
+	if _, ok := expected.(jsonIgnored); ok {
+		return diffs
+	}
+
+	switch exp := expected.(type) {
+	case map[string]any:
+		g, ok := got.(map[string]any)
+		if !ok {
+			break
+		}
+		for _, k := range sortedJSONKeys(exp) {
+			sub, ok := g[k]
+			if !ok {
+				diffs = append(diffs, fmt.Sprintf("%s: missing, expected %s",
+					jsonPathKey(path, k), jsonString(exp[k])))
+				continue
+			}
+			diffs = jsonDiff(diffs, jsonPathKey(path, k), sub, exp[k], subset)
+		}
+		if !subset {
+			for _, k := range sortedJSONKeys(g) {
+				if _, ok := exp[k]; !ok {
+					diffs = append(diffs, fmt.Sprintf("%s: unexpected %s",
+						jsonPathKey(path, k), jsonString(g[k])))
+				}
+			}
+		}
+		return diffs
+
+	case []any:
+		g, ok := got.([]any)
+		if !ok {
+			break
+		}
+		if len(g) != len(exp) {
+			diffs = append(diffs, fmt.Sprintf("%s: got %d items, expected %d",
+				path, len(g), len(exp)))
+		}
+		for i := 0; i < len(g) && i < len(exp); i++ {
+			diffs = jsonDiff(diffs, jsonPathIndex(path, i), g[i], exp[i], subset)
+		}
+		return diffs
+
+	case json.Number:
+		if g, ok := got.(json.Number); ok {
+			if g == exp {
+				return diffs
+			}
+			gf, gerr := g.Float64()
+			ef, eerr := exp.Float64()
+			if gerr == nil && eerr == nil && gf == ef {
+				return diffs
+			}
+		}
+
+	default: // string, bool & nil
+		if got == expected {
+			return diffs
+		}
+	}
+	return append(diffs, fmt.Sprintf("%s: got %s, expected %s",
+		path, jsonString(got), jsonString(expected)))
+}
+
+// decodeJSON decodes b, using [json.Number] for numbers.
+func decodeJSON(b []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("unexpected data after top-level value")
+	}
+	return v, nil
+}
+
+// jsonExpected normalizes expected. If raw is true, string, []byte
+// and [json.RawMessage] are considered as JSON documents, otherwise
+// only [json.RawMessage] is. A panic occurs if expected cannot be
+// encoded or decoded, prefixed by caller.
+func jsonExpected(caller string, expected any, raw bool) any {
+	var (
+		b   []byte
+		err error
+	)
+	switch e := expected.(type) {
+	case json.RawMessage:
+		b = e
+	case string:
+		if raw {
+			b = []byte(e)
+			break
+		}
+		b, err = json.Marshal(e)
+	case []byte:
+		if raw {
+			b = e
+			break
+		}
+		b, err = json.Marshal(e)
+	default:
+		b, err = json.Marshal(e)
+	}
+	var v any
+	if err == nil {
+		v, err = decodeJSON(b)
+	}
+	if err != nil {
+		panic(fmt.Sprintf("%s bad expected JSON: %s", caller, err))
+	}
+	return v
+}
+
+// newJSONMatcher returns a [Matcher] decoding request body as JSON,
+// then calling check to get the differences with the expected value.
+// The request is matched if there is no difference.
+func newJSONMatcher(check func(got any) []string) Matcher {
+	diff := func(req *http.Request) (int, string) {
+		body, err := readDecodedBody(req)
+		var got any
+		if err == nil {
+			got, err = decodeJSON(body)
+		}
+		if err != nil {
+			return maxMatcherDistance, "$: body is not valid JSON: " + err.Error()
+		}
+		diffs := check(got)
+		return len(diffs), strings.Join(diffs, "\n")
+	}
+	m := NewMatcher("", func(req *http.Request) bool {
+		distance, _ := diff(req)
+		return distance == 0
+	})
+	m.diff = diff
+	return m
+}
+
+func jsonBodyMatcher(caller string, expected any, subset bool, ignore []string) Matcher {
+	exp := jsonExpected(caller, expected, true)
+	paths := make([][]jsonPathElem, len(ignore))
+	for i, path := range ignore {
+		paths[i] = parseJSONPath(caller, path)
+		jsonIgnore(exp, paths[i])
+	}
+	return newJSONMatcher(func(got any) []string {
+		for _, path := range paths {
+			jsonIgnore(got, path)
+		}
+		return jsonDiff(nil, "$", got, exp, subset)
+	})
+}
+
+// JSONBodyIs returns a [Matcher] checking that request body is a JSON
+// document structurally equal to expected: objects keys order and
+// whitespaces do not matter, numbers are compared by value.
+//
+// expected can be a JSON document as string, []byte or
+// [json.RawMessage], or any other value that is first JSON encoded.
+// If it cannot be encoded or decoded, a panic occurs.
+//
+// ignore is a list of JSON paths, like "$.created_at" or
+// "$.items[*].id", targeting volatile values, such as timestamps or
+// generated IDs. They are ignored both in request body and in
+// expected. A path is made of ".key", `["key"]`, "[index]" and
+// wildcards ".*" and "[*]" elements. The leading "$" is optional. A
+// panic occurs if a path cannot be parsed.
+//
+// When no responder is found, the differences between the request
+// body and expected are reported in the returned error, if this
+// [Matcher] is the closest one.
+//
+// The name of the returned [Matcher] is auto-generated (see [NewMatcher]).
+// To name it explicitly, use [Matcher.WithName] as in:
+//
+//	JSONBodyIs(`{"name":"Bob","age":42}`, "$.id").WithName("10-json-bob")
+func JSONBodyIs(expected any, ignore ...string) Matcher {
+	return jsonBodyMatcher("JSONBodyIs", expected, false, ignore)
+}
+
+// JSONBodyContains returns a [Matcher] checking that request body is
+// a JSON document containing expected. It works as [JSONBodyIs]
+// except that request objects can contain keys not present in the
+// expected ones, at any level. Arrays still have to contain the same
+// number of items.
+//
+// The name of the returned [Matcher] is auto-generated (see [NewMatcher]).
+// To name it explicitly, use [Matcher.WithName] as in:
+//
+//	JSONBodyContains(map[string]any{"name": "Bob"}).WithName("10-json-bob")
+func JSONBodyContains(expected any, ignore ...string) Matcher {
+	return jsonBodyMatcher("JSONBodyContains", expected, true, ignore)
+}
+
+// JSONPathIs returns a [Matcher] checking that request body is a JSON
+// document in which the value at path is structurally equal to
+// expected. If path contains wildcards, all the targeted values must
+// be equal to expected, and at least one must exist. See
+// [JSONBodyIs] for path syntax.
+//
+// Unlike [JSONBodyIs], expected is always JSON encoded first, so a
+// string is a JSON string, except if it is a [json.RawMessage].
+//
+// The name of the returned [Matcher] is auto-generated (see [NewMatcher]).
+// To name it explicitly, use [Matcher.WithName] as in:
+//
+//	JSONPathIs("$.user.name", "Bob").WithName("10-user-bob")
+func JSONPathIs(path string, expected any) Matcher {
+	elems := parseJSONPath("JSONPathIs", path)
+	exp := jsonExpected("JSONPathIs", expected, false)
+	return newJSONMatcher(func(got any) []string {
+		var (
+			diffs []string
+			found bool
+		)
+		jsonSelect(got, elems, "$", func(p string, v any) {
+			found = true
+			diffs = jsonDiff(diffs, p, v, exp, false)
+		})
+		if !found {
+			return []string{path + ": not found"}
+		}
+		return diffs
+	})
+}
+
+// JSONPathExists returns a [Matcher] checking that request body is a
+// JSON document containing a value at path. See [JSONBodyIs] for
+// path syntax.
+//
+// The name of the returned [Matcher] is auto-generated (see [NewMatcher]).
+// To name it explicitly, use [Matcher.WithName] as in:
+//
+//	JSONPathExists("$.items[0].id").WithName("10-has-items")
+func JSONPathExists(path string) Matcher {
+	elems := parseJSONPath("JSONPathExists", path)
+	return newJSONMatcher(func(got any) []string {
+		var found bool
+		jsonSelect(got, elems, "$", func(string, any) { found = true })
+		if !found {
+			return []string{path + ": not found"}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,149 @@
+package httpmock_test
+
+import (
+	"encoding/json"
+	"io/ioutil" //nolint: staticcheck
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/td"
+
+	"github.com/jarcoal/httpmock"
+)
+
+func TestJSONMatchers(t *testing.T) {
+	assert := td.Assert(t)
+
+	const body = `{
+  "id": "4f2a",
+  "name": "Bob",
+  "age": 42,
+  "tags": ["a", "b"],
+  "items": [
+    {"id": 1, "created_at": "2026-10-18T10:00:00Z", "qty": 2.0},
+    {"id": 2, "created_at": "2026-10-18T11:00:00Z", "qty": 1}
+  ],
+  "odd key": null
+}`
+
+	for _, tc := range []struct {
+		name     string
+		matcher  httpmock.Matcher
+		expected bool
+	}{
+		{"is", httpmock.JSONBodyIs(body), true},
+		{"is+reordered", httpmock.JSONBodyIs(`{"odd key":null,"tags":["a","b"],"age":42.0,"name":"Bob","id":"4f2a","items":[{"qty":2,"created_at":"2026-10-18T10:00:00Z","id":1},{"qty":1,"created_at":"2026-10-18T11:00:00Z","id":2}]}`), true},
+		{"is+ignore", httpmock.JSONBodyIs(map[string]interface{}{
+			"name": "Bob", "age": 42, "tags": []string{"a", "b"},
+			"items": []map[string]int{{"id": 1, "qty": 2}, {"id": 2, "qty": 1}},
+		}, "$.id", "items[*].created_at", `$["odd key"]`), true},
+		{"is+ignore-array-item", httpmock.JSONBodyIs(
+			`{"name":"Bob","tags":["a","z"]}`, "$.id", "age", "items", "$['odd key']", "tags[1]"), true},
+		{"is+missing", httpmock.JSONBodyIs(`{"name":"Bob"}`), false},
+		{"is+differ", httpmock.JSONBodyIs(body, "$.*.x", "$.name[0]"), true},
+		{"contains", httpmock.JSONBodyContains(`{"name":"Bob","items":[{"id":1},{"id":2}]}`), true},
+		{"contains+raw", httpmock.JSONBodyContains(json.RawMessage(`{"age":42}`)), true},
+		{"contains+bytes", httpmock.JSONBodyContains([]byte(`{"age":43}`)), false},
+		{"contains+less-items", httpmock.JSONBodyContains(`{"items":[{"id":1}]}`), false},
+		{"path", httpmock.JSONPathIs("$.items[1].id", 2), true},
+		{"path+string", httpmock.JSONPathIs("name", "Bob"), true},
+		{"path+raw", httpmock.JSONPathIs("tags", json.RawMessage(`["a","b"]`)), true},
+		{"path+wildcard", httpmock.JSONPathIs("$.items[*].id", 1), false},
+		{"path+null", httpmock.JSONPathIs(`$["odd key"]`, nil), true},
+		{"path+not-found", httpmock.JSONPathIs("$.items[2].id", 3), false},
+		{"exists", httpmock.JSONPathExists("$.items[0].created_at"), true},
+		{"exists+wildcard", httpmock.JSONPathExists("$.*[1]"), true},
+		{"exists+no", httpmock.JSONPathExists("$.name.first"), false},
+	} {
+		assert.Run(tc.name, func(assert *td.T) {
+			req, err := http.NewRequest("POST", "http://z.tld/", strings.NewReader(body))
+			td.Require(t).CmpNoError(err)
+			assert.Cmp(tc.matcher.Check(req), tc.expected)
+
+			// body is still readable
+			b, err := ioutil.ReadAll(req.Body)
+			assert.CmpNoError(err)
+			assert.Cmp(string(b), body)
+		})
+	}
+
+	req, err := http.NewRequest("POST", "http://z.tld/", strings.NewReader(`{"a":1} {}`))
+	td.Require(t).CmpNoError(err)
+	assert.False(httpmock.JSONPathExists("a").Check(req))
+
+	assert.CmpPanic(func() { httpmock.JSONBodyIs(`{`) },
+		td.HasPrefix("JSONBodyIs bad expected JSON: "))
+	assert.CmpPanic(func() { httpmock.JSONBodyContains(func() {}) },
+		td.HasPrefix("JSONBodyContains bad expected JSON: "))
+	for _, path := range []string{"$.", "$x", "a[", "a[-1]", "a[x]", "a..b", `a["b]`} {
+		assert.CmpPanic(func() { httpmock.JSONPathExists(path) },
+			`JSONPathExists bad JSON path "`+strings.ReplaceAll(path, `"`, `\"`)+`"`, path)
+	}
+}
+
+func TestJSONMatcherDiff(t *testing.T) {
+	assert, require := td.AssertRequire(t)
+
+	mt := httpmock.NewMockTransport()
+	client := &http.Client{Transport: mt}
+
+	mt.RegisterMatcherResponder("POST", "/users",
+		httpmock.JSONBodyIs(`{"name":"Alice","age":30,"tags":["x"]}`).WithName("00-alice"),
+		httpmock.NewStringResponder(201, "alice"))
+	mt.RegisterMatcherResponder("POST", "/users",
+		httpmock.JSONBodyIs(`{"name":"Bob","age":42}`, "$.id").
+			And(httpmock.HeaderIs("X-Trace", "1")).
+			WithName("01-bob"),
+		httpmock.NewStringResponder(201, "bob"))
+	mt.RegisterMatcherResponder("POST", "/users",
+		httpmock.HeaderExists("X-Never").WithName("02-never"),
+		httpmock.NewStringResponder(201, "never"))
+
+	_, err := client.Post("http://z.tld/users", "application/json",
+		strings.NewReader(`{"id":12,"name":"Bobby","age":42,"extra":true}`))
+	assert.Cmp(err, td.Smuggle(
+		func(err error) string { return err.Error() },
+		td.Re(`\APost "http://z.tld/users": no responder found despite 3 matchers: \["00-alice" "01-bob" "02-never"\], closest matcher "01-bob" differs:
+	\$\.name: got "Bobby", expected "Bob"
+	\$\.extra: unexpected true
+	matcher "~[0-9a-f]{10} @github\.com/jarcoal/httpmock_test\.TestJSONMatcherDiff\(\) .*json_test\.go:\d+" did not match\z`)))
+
+	// Only the matcher without diff fails
+	_, err = client.Post("http://z.tld/users", "application/json",
+		strings.NewReader(`{"id":12,"name":"Bob","age":42}`))
+	assert.Contains(err, `closest matcher "01-bob" differs:
+	matcher "~`)
+	assert.HasSuffix(err, `" did not match`)
+
+	_, err = client.Post("http://z.tld/users", "application/json", strings.NewReader(`<xml/>`))
+	assert.HasSuffix(err, `closest matcher "00-alice" differs:
+	$: body is not valid JSON: invalid character '<' looking for beginning of value`)
+
+	// Invalid body distances do not overflow when summed
+	req, err := http.NewRequest("POST", "http://z.tld/users", strings.NewReader(`<xml/>`))
+	require.CmpNoError(err)
+	distance, _ := httpmock.JSONBodyIs(`{"a":1}`).
+		And(httpmock.JSONBodyContains(`{"b":2}`), httpmock.JSONBodyIs(`{}`)).
+		Diff(req)
+	assert.Cmp(distance, httpmock.MaxMatcherDistance)
+
+	// The body can be read by the responder of the matching matcher
+	mt.RegisterMatcherResponder("POST", "/users",
+		httpmock.JSONBodyContains(`{"name":"Bobby"}`).WithName("03-bobby"),
+		func(req *http.Request) (*http.Response, error) {
+			b, err := ioutil.ReadAll(req.Body)
+			return httpmock.NewBytesResponse(201, b), err
+		})
+	resp, err := client.Post("http://z.tld/users", "application/json",
+		strings.NewReader(`{"name":"Bobby"}`))
+	require.CmpNoError(err)
+	assertBody(assert, resp, `{"name":"Bobby"}`)
+
+	// Also reported by NewNotFoundResponder
+	mt.RegisterNoResponder(httpmock.NewNotFoundResponder(nil))
+	_, err = client.Post("http://z.tld/users", "application/json",
+		strings.NewReader(`{"name":"Alice","age":30,"tags":[]}`))
+	assert.Contains(err, `closest matcher "00-alice" differs:
+	$.tags: got 0 items, expected 1`)
+}
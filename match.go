@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil" //nolint: staticcheck
+	"math"
 	"net/http"
 	"runtime"
 	"strings"
@@ -177,11 +178,90 @@ func (mf MatcherFunc) And(mfs ...MatcherFunc) MatcherFunc {
 // Matcher type defines a match case. The zero Matcher{} corresponds
 // to the default case. Otherwise, use [NewMatcher] or any helper
 // building a [Matcher] like [BodyContainsBytes], [BodyContainsBytes],
-// [HeaderExists], [HeaderIs], [HeaderContains] or any of
+// [HeaderExists], [HeaderIs], [HeaderContains], [JSONBodyIs] or any of
 // [github.com/maxatome/tdhttpmock] functions.
 type Matcher struct {
 	name string
 	fn   MatcherFunc // can be nil → means always true
+	diff matcherDiff // can be nil → no diff available
+}
+
+// matcherDiff returns how far req is from matching and a report of
+// the differences, one per line. It is used to explain why no
+// responder has been found.
+type matcherDiff func(req *http.Request) (distance int, report string)
+
+// maxMatcherDistance is the distance returned by a matcherDiff when
+// the request cannot be compared at all, as when its body is invalid.
+// Distances summed by matcherDiffAnd never exceed it.
+const maxMatcherDistance = math.MaxInt32
+
+// matcherDiffAnd returns a matcherDiff summing distances, up to
+// maxMatcherDistance, and joining reports of all ms. A matcher of ms
+// without diff counts for 1 and is reported by name when it does not
+// match: its MatcherFunc is called again for that. nil is returned if
+// no matcher of ms has a diff.
+func matcherDiffAnd(ms []Matcher) matcherDiff {
+	hasDiff := false
+	for _, m := range ms {
+		if m.diff != nil {
+			hasDiff = true
+			break
+		}
+	}
+	if !hasDiff {
+		return nil
+	}
+	if len(ms) == 1 {
+		return ms[0].diff
+	}
+	return func(req *http.Request) (int, string) {
+		var (
+			total   int
+			reports []string
+		)
+		for _, m := range ms {
+			rearmBody(req)
+			if m.diff == nil {
+				if !m.fn(req) {
+					total++
+					reports = append(reports, fmt.Sprintf("matcher %q did not match", m.name))
+				}
+				continue
+			}
+			distance, report := m.diff(req)
+			if distance > maxMatcherDistance-total {
+				total = maxMatcherDistance
+			} else {
+				total += distance
+			}
+			if report != "" {
+				reports = append(reports, report)
+			}
+		}
+		return total, strings.Join(reports, "\n")
+	}
+}
+
+// matcherDiffOr returns a matcherDiff returning the closest of mds.
+// nil is returned if one of mds is nil.
+func matcherDiffOr(mds []matcherDiff) matcherDiff {
+	for _, md := range mds {
+		if md == nil {
+			return nil
+		}
+	}
+	return func(req *http.Request) (int, string) {
+		best, bestReport := -1, ""
+		for _, md := range mds {
+			rearmBody(req)
+			distance, report := md(req)
+			if best < 0 || distance < best {
+				best, bestReport = distance, report
+			}
+		}
+		return best, bestReport
+	}
 }
 
 var matcherID int64
@@ -213,7 +293,8 @@ func NewMatcher(name string, fn MatcherFunc) Matcher {
 }
 
 // BodyContainsBytes returns a [Matcher] checking that request body
-// contains subslice.
+// contains subslice. A body compressed with gzip or deflate, as
+// indicated by the Content-Encoding header, is decompressed first.
 //
 // The name of the returned [Matcher] is auto-generated (see [NewMatcher]).
 // To name it explicitly, use [Matcher.WithName] as in:
@@ -226,14 +307,14 @@ func NewMatcher(name string, fn MatcherFunc) Matcher {
 func BodyContainsBytes(subslice []byte) Matcher {
 	return NewMatcher("",
 		func(req *http.Request) bool {
-			rearmBody(req)
-			b, err := ioutil.ReadAll(req.Body)
+			b, err := readDecodedBody(req)
 			return err == nil && bytes.Contains(b, subslice)
 		})
 }
 
 // BodyContainsString returns a [Matcher] checking that request body
-// contains substr.
+// contains substr. A body compressed with gzip or deflate, as
+// indicated by the Content-Encoding header, is decompressed first.
 //
 // The name of the returned [Matcher] is auto-generated (see [NewMatcher]).
 // To name it explicitly, use [Matcher.WithName] as in:
@@ -246,8 +327,7 @@ func BodyContainsBytes(subslice []byte) Matcher {
 func BodyContainsString(substr string) Matcher {
 	return NewMatcher("",
 		func(req *http.Request) bool {
-			rearmBody(req)
-			b, err := ioutil.ReadAll(req.Body)
+			b, err := readDecodedBody(req)
 			return err == nil && bytes.Contains(b, []byte(substr))
 		})
 }
@@ -311,7 +391,9 @@ func (m Matcher) Name() string {
 
 // WithName returns a new [Matcher] based on m with name name.
 func (m Matcher) WithName(name string) Matcher {
-	return NewMatcher(name, m.fn)
+	nm := NewMatcher(name, m.fn)
+	nm.diff = m.diff
+	return nm
 }
 
 // Check returns true if req is matched by m.
@@ -330,13 +412,17 @@ func (m Matcher) Or(ms ...Matcher) Matcher {
 	}
 	mfs := make([]MatcherFunc, 1, len(ms)+1)
 	mfs[0] = m.fn
+	mds := make([]matcherDiff, 1, len(ms)+1)
+	mds[0] = m.diff
 	for _, cur := range ms {
 		if cur.fn == nil {
 			return Matcher{}
 		}
 		mfs = append(mfs, cur.fn)
+		mds = append(mds, cur.diff)
 	}
 	m.fn = matcherFuncOr(mfs)
+	m.diff = matcherDiffOr(mds)
 	return m
 }
 
@@ -344,21 +430,29 @@ func (m Matcher) Or(ms ...Matcher) Matcher {
 // succeeds if all of m and ms succeed. Note that a [Matcher] also
 // succeeds if [Matcher] [MatcherFunc] is nil. The name of returned
 // [Matcher] is m's one if the empty/default [Matcher] is returned.
+//
+// When no responder matches a request, the [MatcherFunc] of m and ms
+// can be called again to report why the returned [Matcher] did not
+// match, so they should not have side effects.
 func (m Matcher) And(ms ...Matcher) Matcher {
 	if len(ms) == 0 {
 		return m
 	}
 	mfs := make([]MatcherFunc, 0, len(ms)+1)
+	parts := make([]Matcher, 0, len(ms)+1)
 	if m.fn != nil {
 		mfs = append(mfs, m.fn)
+		parts = append(parts, m)
 	}
 	for _, cur := range ms {
 		if cur.fn != nil {
 			mfs = append(mfs, cur.fn)
+			parts = append(parts, cur)
 		}
 	}
 	m.fn = matcherFuncAnd(mfs)
 	if m.fn != nil {
+		m.diff = matcherDiffAnd(parts)
 		return m
 	}
 	return Matcher{}
@@ -457,6 +551,31 @@ func (mrs matchResponders) findMatchResponder(req *http.Request) *matchResponder
 	return nil
 }
 
+// closest returns the name of the matcher of mrs the closest to
+// match req and the report of its differences. Only matchers able to
+// produce a diff are taken into account. If none, name is empty.
+func (mrs matchResponders) closest(req *http.Request) (name, report string) {
+	copyBody := &bodyCopyOnRead{body: req.Body}
+	req.Body = copyBody
+	defer func() {
+		copyBody.rearm()
+		req.Body = copyBody.body
+	}()
+
+	best := -1
+	for _, mr := range mrs {
+		if mr.matcher.diff == nil {
+			continue
+		}
+		copyBody.rearm()
+		distance, r := mr.matcher.diff(req)
+		if best < 0 || distance < best {
+			best, name, report = distance, mr.matcher.name, r
+		}
+	}
+	return
+}
+
 type matchRouteKey struct {
 	internal.RouteKey
 	name string
@@ -477,6 +596,34 @@ func rearmBody(req *http.Request) {
 	}
 }
 
+// readBody returns the whole req body, leaving it readable again
+// from its beginning. Inside a MatcherFunc, req.Body is rearmed
+// before and after reading it. Elsewhere, typically inside a
+// Responder, req.Body is replaced by a buffer containing the read
+// content.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	if _, ok := req.Body.(interface{ rearm() }); ok {
+		rearmBody(req)
+		defer rearmBody(req)
+		return ioutil.ReadAll(req.Body)
+	}
+
+	if buf, ok := req.Body.(buffer); ok {
+		buf.Seek(0, io.SeekStart)       //nolint:errcheck
+		defer buf.Seek(0, io.SeekStart) //nolint:errcheck
+		return ioutil.ReadAll(buf)
+	}
+
+	b, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	req.Body = buffer{bytes.NewReader(b)}
+	return b, err
+}
+
 type buffer struct {
 	*bytes.Reader
 }
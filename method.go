@@ -0,0 +1,108 @@
+package httpmock
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// SetMethodFallback enables or disables the automatic HEAD and 405
+// Method Not Allowed mode of m. When enabled and no responder is
+// registered for the method of a request, but some are for other
+// methods on the same URL:
+//   - a HEAD request is handled by the GET responder, and the
+//     response is returned with its headers kept and an empty body,
+//     as net/http does. The call is counted for the GET responder;
+//   - otherwise, a 405 Method Not Allowed response is returned, with
+//     an Allow header listing the registered methods, HEAD included
+//     if GET is registered. It takes precedence over the responder
+//     registered with [MockTransport.RegisterNoResponder], and is
+//     counted as a call to it in [MockTransport.GetCallCountInfo].
+//
+// When some responders are registered for the method but none of
+// their matchers matches, the usual no responder behavior applies.
+//
+//	mt := httpmock.NewMockTransport()
+//	mt.SetMethodFallback(true)
+//	mt.RegisterResponder("GET", "/items", httpmock.NewStringResponder(200, "[]"))
+//	mt.RegisterResponder("POST", "/items", httpmock.NewStringResponder(201, "{}"))
+//	// HEAD /items → 200 with GET headers and an empty body
+//	// DELETE /items → 405 with "Allow: GET, HEAD, POST"
+func (m *MockTransport) SetMethodFallback(enable bool) {
+	m.mu.Lock()
+	m.methodFallback = enable
+	m.mu.Unlock()
+}
+
+func (m *MockTransport) methodFallbackEnabled() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.methodFallback
+}
+
+// allowedMethods returns the methods for which responders are
+// registered for u.
+func (m *MockTransport) allowedMethods(u *url.URL) map[string]bool {
+	methods := map[string]bool{}
+	m.mu.RLock()
+	for rk := range m.responders {
+		methods[rk.Method] = true
+	}
+	for _, rr := range m.regexpResponders {
+		methods[rr.method] = true
+	}
+	m.mu.RUnlock()
+
+	allowed := map[string]bool{}
+	for method := range methods {
+		if found, _ := m.findResponders(method, u, 0); found.responders != nil {
+			allowed[method] = true
+		}
+	}
+	return allowed
+}
+
+// headFromGet handles the HEAD request req using the GET responder
+// registered for its URL, then drops the response body.
+func (m *MockTransport) headFromGet(req *http.Request) (*http.Response, error) {
+	getReq := req.Clone(req.Context())
+	getReq.Method = http.MethodGet
+
+	resp, err := m.roundTrip(getReq)
+	if err != nil || resp == nil {
+		return resp, err
+	}
+	if resp.Body != nil {
+		resp.Body.Close() //nolint: errcheck
+	}
+	nr := *resp
+	nr.Body = http.NoBody
+	if nr.Request != nil {
+		nr.Request = req
+	}
+	return &nr, nil
+}
+
+// newMethodNotAllowedResponder returns a [Responder] responding with
+// a 405 Method Not Allowed status and an Allow header listing allowed
+// methods, plus HEAD if GET is allowed.
+func newMethodNotAllowedResponder(allowed map[string]bool) Responder {
+	methods := make([]string, 0, len(allowed)+1)
+	for method := range allowed {
+		methods = append(methods, method)
+	}
+	if allowed[http.MethodGet] && !allowed[http.MethodHead] {
+		methods = append(methods, http.MethodHead)
+	}
+	sort.Strings(methods)
+	allow := strings.Join(methods, ", ")
+
+	return func(req *http.Request) (*http.Response, error) {
+		resp := NewStringResponse(http.StatusMethodNotAllowed, "")
+		resp.Header.Set("Allow", allow)
+		resp.ContentLength = 0
+		resp.Request = req
+		return resp, nil
+	}
+}
@@ -0,0 +1,104 @@
+package httpmock_test
+
+import (
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/td"
+
+	"github.com/jarcoal/httpmock"
+)
+
+func TestSetMethodFallback(t *testing.T) {
+	assert, require := td.AssertRequire(t)
+
+	mt := httpmock.NewMockTransport()
+	mt.RegisterResponder("GET", "http://z.tld/items",
+		httpmock.NewStringResponder(200, "[1,2]").
+			HeaderSet(http.Header{"X-Total": {"2"}}).
+			SetContentLength())
+	mt.RegisterResponder("POST", "http://z.tld/items", httpmock.NewStringResponder(201, "{}"))
+	mt.RegisterRegexpResponder("DELETE", regexp.MustCompile(`/items\z`),
+		httpmock.NewStringResponder(204, ""))
+	mt.RegisterMatcherResponder("PUT", "http://z.tld/items",
+		httpmock.BodyContainsString("ok"), httpmock.NewStringResponder(200, "updated"))
+	mt.RegisterResponder("POST", "http://z.tld/upload", httpmock.NewStringResponder(201, ""))
+
+	do := func(method, url string) (*http.Response, error) {
+		req, err := http.NewRequest(method, url, nil)
+		require.CmpNoError(err)
+		return mt.RoundTrip(req)
+	}
+
+	// Disabled by default
+	_, err := do("HEAD", "http://z.tld/items")
+	assert.Cmp(err, td.Re(`no responder found for method "HEAD"`))
+
+	mt.SetMethodFallback(true)
+
+	// HEAD served by GET responder
+	resp, err := do("HEAD", "http://z.tld/items")
+	require.CmpNoError(err)
+	assert.Cmp(resp.StatusCode, 200)
+	assert.Cmp(resp.Header.Get("X-Total"), "2")
+	assert.Cmp(resp.ContentLength, int64(5))
+	assert.Cmp(resp.Body, http.NoBody)
+
+	// 405 with accurate Allow header
+	resp, err = do("PATCH", "http://z.tld/items")
+	require.CmpNoError(err)
+	assert.Cmp(resp.StatusCode, 405)
+	assert.Cmp(resp.Header.Get("Allow"), "DELETE, GET, HEAD, POST, PUT")
+	assertBody(assert, resp, "")
+
+	resp, err = do("GET", "http://z.tld/upload")
+	require.CmpNoError(err)
+	assert.Cmp(resp.StatusCode, 405)
+	assert.Cmp(resp.Header.Get("Allow"), "POST")
+
+	resp, err = do("HEAD", "http://z.tld/upload")
+	require.CmpNoError(err)
+	assert.Cmp(resp.StatusCode, 405)
+
+	// Responder registered for the method but matcher mismatch
+	_, err = do("PUT", "http://z.tld/items")
+	assert.Cmp(err, td.Re(`matcher`))
+
+	// Unknown URL
+	_, err = do("GET", "http://z.tld/unknown")
+	assert.Cmp(err, httpmock.NoResponderFound)
+
+	assert.Cmp(mt.GetCallCountInfo(), td.SuperMapOf(map[string]int{
+		"GET http://z.tld/items": 1,
+		"NO_RESPONDER":           3,
+	}, nil))
+
+	// Takes precedence over the no responder
+	mt.RegisterNoResponder(httpmock.NewStringResponder(404, "not found"))
+	resp, err = do("PATCH", "http://z.tld/items")
+	require.CmpNoError(err)
+	assert.Cmp(resp.StatusCode, 405)
+
+	mt.SetMethodFallback(false)
+	resp, err = do("PATCH", "http://z.tld/items")
+	require.CmpNoError(err)
+	assert.Cmp(resp.StatusCode, 404)
+}
+
+func TestSetMethodFallbackTrackBodies(t *testing.T) {
+	assert, require := td.AssertRequire(t)
+
+	mt := httpmock.NewMockTransport()
+	mt.SetMethodFallback(true)
+	mt.TrackBodies(true)
+	mt.RegisterResponder("GET", "http://z.tld/", httpmock.NewStringResponder(200, "body"))
+
+	req, err := http.NewRequest("HEAD", "http://z.tld/", nil)
+	require.CmpNoError(err)
+	resp, err := mt.RoundTrip(req)
+	require.CmpNoError(err)
+	assert.Cmp(resp.StatusCode, 200)
+	assert.Cmp(resp.Body, http.NoBody)
+	assert.CmpNoError(mt.CheckBodies())
+}
@@ -0,0 +1,221 @@
+package httpmock
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil" //nolint: staticcheck
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+)
+
+// MultipartPart is a part of a multipart body. It is returned by
+// [ParseMultipartRequest], used as criteria by [MultipartHasPart]
+// and to build responses by [NewMultipartResponse].
+type MultipartPart struct {
+	// FormName is the name parameter of the Content-Disposition
+	// header, when it is "form-data".
+	FormName string
+	// FileName is the filename parameter of the Content-Disposition
+	// header.
+	FileName string
+	// ContentType is the Content-Type header of the part.
+	ContentType string
+	// Header contains all the headers of the part.
+	Header textproto.MIMEHeader
+	// Content is the body of the part.
+	Content []byte
+}
+
+// ParseMultipartRequest parses the multipart body of req and returns
+// its parts. The body is restored, so it can be read again after
+// this call. It is typically used inside a [Responder]:
+//
+//	httpmock.RegisterResponder("POST", "/upload",
+//	  func(req *http.Request) (*http.Response, error) {
+//	    parts, err := httpmock.ParseMultipartRequest(req)
+//	    if err != nil {
+//	      return httpmock.NewStringResponse(400, err.Error()), nil
+//	    }
+//	    return httpmock.NewJsonResponse(201, map[string]any{"parts": len(parts)})
+//	  })
+//
+// An error is returned if req Content-Type is not a multipart one or
+// if its body cannot be parsed.
+func ParseMultipartRequest(req *http.Request) ([]MultipartPart, error) {
+	mediaType, params, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(mediaType, "multipart/") {
+		return nil, fmt.Errorf("not a multipart content type: %s", mediaType)
+	}
+	if params["boundary"] == "" {
+		return nil, errors.New("multipart boundary not found")
+	}
+
+	body, err := readDecodedBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var parts []MultipartPart
+	mr := multipart.NewReader(bytes.NewReader(body), params["boundary"])
+	for {
+		p, err := mr.NextPart()
+		if err != nil {
+			if err == io.EOF {
+				return parts, nil
+			}
+			return nil, err
+		}
+		content, err := ioutil.ReadAll(p)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, MultipartPart{
+			FormName:    p.FormName(),
+			FileName:    p.FileName(),
+			ContentType: p.Header.Get("Content-Type"),
+			Header:      p.Header,
+			Content:     content,
+		})
+	}
+}
+
+// matches returns true if got matches all non-empty fields of p.
+func (p MultipartPart) matches(got MultipartPart) bool {
+	if p.FormName != "" && p.FormName != got.FormName {
+		return false
+	}
+	if p.FileName != "" && p.FileName != got.FileName {
+		return false
+	}
+	if p.ContentType != "" {
+		expected, _, _ := mime.ParseMediaType(p.ContentType)
+		gotType, _, _ := mime.ParseMediaType(got.ContentType)
+		if expected != gotType {
+			return false
+		}
+	}
+	for key, values := range p.Header {
+		if strings.Join(got.Header[textproto.CanonicalMIMEHeaderKey(key)], ",") != strings.Join(values, ",") {
+			return false
+		}
+	}
+	return p.Content == nil || bytes.Equal(p.Content, got.Content)
+}
+
+// MultipartHasPart returns a [Matcher] checking that the request has
+// a multipart body containing at least one part matching all the
+// non-empty fields of part:
+//   - FormName and FileName are compared as is;
+//   - ContentType is compared without its parameters;
+//   - each Header key is compared case-insensitively, its values as is;
+//   - Content is compared as is if non-nil.
+//
+// The name of the returned [Matcher] is auto-generated (see [NewMatcher]).
+// To name it explicitly, use [Matcher.WithName] as in:
+//
+//	MultipartHasPart(httpmock.MultipartPart{
+//	  FormName:    "avatar",
+//	  FileName:    "me.png",
+//	  ContentType: "image/png",
+//	}).WithName("10-avatar")
+func MultipartHasPart(part MultipartPart) Matcher {
+	return NewMatcher("",
+		func(req *http.Request) bool {
+			parts, err := ParseMultipartRequest(req)
+			if err != nil {
+				return false
+			}
+			for _, got := range parts {
+				if part.matches(got) {
+					return true
+				}
+			}
+			return false
+		})
+}
+
+// MultipartFieldIs returns a [Matcher] checking that the request has
+// a multipart body containing a form field name set to value.
+//
+// The name of the returned [Matcher] is auto-generated (see [NewMatcher]).
+// To name it explicitly, use [Matcher.WithName] as in:
+//
+//	MultipartFieldIs("title", "Hello").WithName("10-title-is-hello")
+func MultipartFieldIs(name, value string) Matcher {
+	return MultipartHasPart(MultipartPart{
+		FormName: name,
+		Content:  []byte(value),
+	})
+}
+
+// NewMultipartResponse creates an [*http.Response] with a multipart
+// body made of parts. Also accepts an HTTP status code and the
+// multipart subtype, as "mixed", "related" or "form-data". The
+// Content-Type header is set accordingly, with a random boundary.
+//
+// For each part, the Content-Type header is set to its ContentType
+// field if non-empty. Unless present in its Header field, the
+// Content-Disposition header is set to "form-data" if FormName is
+// non-empty, else to "attachment" if FileName is non-empty, with the
+// corresponding name and filename parameters.
+func NewMultipartResponse(status int, subtype string, parts ...MultipartPart) *http.Response {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for _, p := range parts {
+		h := make(textproto.MIMEHeader, len(p.Header)+2)
+		for k, v := range p.Header {
+			h[textproto.CanonicalMIMEHeaderKey(k)] = append([]string(nil), v...)
+		}
+		if p.ContentType != "" {
+			h.Set("Content-Type", p.ContentType)
+		}
+		if h.Get("Content-Disposition") == "" {
+			params := map[string]string{}
+			if p.FileName != "" {
+				params["filename"] = p.FileName
+			}
+			if p.FormName != "" {
+				params["name"] = p.FormName
+				h.Set("Content-Disposition", mime.FormatMediaType("form-data", params))
+			} else if p.FileName != "" {
+				h.Set("Content-Disposition", mime.FormatMediaType("attachment", params))
+			}
+		}
+		w, _ := mw.CreatePart(h) // never fails with a bytes.Buffer
+		w.Write(p.Content)       //nolint: errcheck
+	}
+	mw.Close()
+
+	resp := &http.Response{
+		Status:        strconv.Itoa(status),
+		StatusCode:    status,
+		Body:          NewRespBodyFromBytes(body.Bytes()),
+		Header:        http.Header{},
+		ContentLength: -1,
+	}
+	resp.Header.Set("Content-Type",
+		mime.FormatMediaType("multipart/"+subtype, map[string]string{"boundary": mw.Boundary()}))
+	return resp
+}
+
+// NewMultipartResponder creates a [Responder] from a multipart body
+// made of parts, a multipart subtype and a status code. See
+// [NewMultipartResponse] for details.
+//
+//	httpmock.RegisterResponder("GET", "/batch",
+//	  httpmock.NewMultipartResponder(200, "mixed",
+//	    httpmock.MultipartPart{ContentType: "application/json", Content: []byte(`{"id":1}`)},
+//	    httpmock.MultipartPart{ContentType: "application/json", Content: []byte(`{"id":2}`)},
+//	  ))
+func NewMultipartResponder(status int, subtype string, parts ...MultipartPart) Responder {
+	return ResponderFromResponse(NewMultipartResponse(status, subtype, parts...))
+}
@@ -0,0 +1,181 @@
+package httpmock_test
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/td"
+
+	"github.com/jarcoal/httpmock"
+)
+
+func newMultipartRequest(t testing.TB) *http.Request {
+	t.Helper()
+	require := td.Require(t)
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	require.CmpNoError(mw.WriteField("title", "Hello"))
+
+	h := textproto.MIMEHeader{}
+	h.Set("Content-Disposition", `form-data; name="avatar"; filename="me.png"`)
+	h.Set("Content-Type", "image/png")
+	w, err := mw.CreatePart(h)
+	require.CmpNoError(err)
+	w.Write([]byte("PNG...")) //nolint: errcheck
+	require.CmpNoError(mw.Close())
+
+	req, err := http.NewRequest("POST", "http://z.tld/upload", &body)
+	require.CmpNoError(err)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func TestParseMultipartRequest(t *testing.T) {
+	assert, require := td.AssertRequire(t)
+
+	req := newMultipartRequest(t)
+	parts, err := httpmock.ParseMultipartRequest(req)
+	require.CmpNoError(err)
+	assert.Cmp(parts, td.Slice([]httpmock.MultipartPart{}, td.ArrayEntries{
+		0: td.Struct(httpmock.MultipartPart{
+			FormName: "title",
+			Content:  []byte("Hello"),
+		}, td.StructFields{"FileName": "", "ContentType": ""}),
+		1: td.Struct(httpmock.MultipartPart{
+			FormName:    "avatar",
+			FileName:    "me.png",
+			ContentType: "image/png",
+			Content:     []byte("PNG..."),
+		}, td.StructFields{
+			"Header": td.Code(func(h textproto.MIMEHeader) bool {
+				return h.Get("Content-Type") == "image/png"
+			}),
+		}),
+	}))
+
+	// Body can be read again
+	again, err := httpmock.ParseMultipartRequest(req)
+	require.CmpNoError(err)
+	assert.Cmp(again, parts)
+
+	// Errors
+	req, err = http.NewRequest("POST", "http://z.tld/upload", strings.NewReader("x"))
+	require.CmpNoError(err)
+	_, err = httpmock.ParseMultipartRequest(req)
+	assert.CmpError(err)
+
+	req.Header.Set("Content-Type", "application/json")
+	_, err = httpmock.ParseMultipartRequest(req)
+	assert.String(err, "not a multipart content type: application/json")
+
+	req.Header.Set("Content-Type", "multipart/form-data")
+	_, err = httpmock.ParseMultipartRequest(req)
+	assert.String(err, "multipart boundary not found")
+}
+
+func TestMultipartMatchers(t *testing.T) {
+	assert, require := td.AssertRequire(t)
+
+	mt := httpmock.NewMockTransport()
+	client := &http.Client{Transport: mt}
+
+	mt.RegisterMatcherResponder("POST", "/upload",
+		httpmock.MultipartFieldIs("title", "Hello").
+			And(httpmock.MultipartHasPart(httpmock.MultipartPart{
+				FormName:    "avatar",
+				FileName:    "me.png",
+				ContentType: "image/png; foo=bar",
+				Content:     []byte("PNG..."),
+			})),
+		func(req *http.Request) (*http.Response, error) {
+			parts, err := httpmock.ParseMultipartRequest(req)
+			if err != nil {
+				return nil, err
+			}
+			return httpmock.NewStringResponse(201, parts[1].FileName), nil
+		})
+	mt.RegisterMatcherResponder("POST", "/upload",
+		httpmock.MultipartFieldIs("title", "Bye").WithName("bye"),
+		httpmock.NewStringResponder(200, "bye"))
+
+	resp, err := client.Do(newMultipartRequest(t))
+	require.CmpNoError(err)
+	assert.Cmp(resp.StatusCode, 201)
+	assertBody(assert, resp, "me.png")
+
+	for _, m := range []httpmock.Matcher{
+		httpmock.MultipartFieldIs("title", "Bye"),
+		httpmock.MultipartHasPart(httpmock.MultipartPart{FileName: "you.png"}),
+		httpmock.MultipartHasPart(httpmock.MultipartPart{ContentType: "image/gif"}),
+		httpmock.MultipartHasPart(httpmock.MultipartPart{
+			Header: textproto.MIMEHeader{"Content-Type": {"text/plain"}},
+		}),
+		httpmock.MultipartHasPart(httpmock.MultipartPart{
+			Header: textproto.MIMEHeader{"Content-Type": {"IMAGE/PNG"}},
+		}),
+	} {
+		assert.False(m.Check(newMultipartRequest(t)))
+	}
+	assert.True(httpmock.MultipartHasPart(httpmock.MultipartPart{
+		Header: textproto.MIMEHeader{"content-type": {"image/png"}},
+	}).Check(newMultipartRequest(t)))
+
+	// Not multipart
+	req, err := http.NewRequest("POST", "http://z.tld/upload", strings.NewReader("title=Hello"))
+	require.CmpNoError(err)
+	assert.False(httpmock.MultipartFieldIs("title", "Hello").Check(req))
+}
+
+func TestNewMultipartResponder(t *testing.T) {
+	assert, require := td.AssertRequire(t)
+
+	responder := httpmock.NewMultipartResponder(200, "mixed",
+		httpmock.MultipartPart{ContentType: "application/json", Content: []byte(`{"id":1}`)},
+		httpmock.MultipartPart{FileName: "a.txt", Content: []byte("A")},
+		httpmock.MultipartPart{FormName: "b", FileName: "b.txt", Content: []byte("B")},
+		httpmock.MultipartPart{
+			Header:  textproto.MIMEHeader{"content-disposition": {"inline"}},
+			Content: []byte("C"),
+		},
+	)
+
+	for i := 0; i < 2; i++ {
+		req, err := http.NewRequest("GET", "http://z.tld/batch", nil)
+		require.CmpNoError(err)
+		resp, err := responder(req)
+		require.CmpNoError(err)
+		assert.Cmp(resp.Header.Get("Content-Type"), td.HasPrefix("multipart/mixed; boundary="))
+
+		// Parse it as a request to check it
+		req.Header.Set("Content-Type", resp.Header.Get("Content-Type"))
+		req.Body = resp.Body
+		parts, err := httpmock.ParseMultipartRequest(req)
+		require.CmpNoError(err)
+		assert.Cmp(parts, td.Slice([]httpmock.MultipartPart{}, td.ArrayEntries{
+			0: td.Struct(httpmock.MultipartPart{
+				ContentType: "application/json",
+				Content:     []byte(`{"id":1}`),
+			}, td.StructFields{"FormName": "", "FileName": ""}),
+			1: td.Struct(httpmock.MultipartPart{FileName: "a.txt", Content: []byte("A")},
+				td.StructFields{
+					"FormName": "",
+					"Header": td.Code(func(h textproto.MIMEHeader) bool {
+						return h.Get("Content-Disposition") == "attachment; filename=a.txt"
+					}),
+				}),
+			2: td.Struct(httpmock.MultipartPart{FormName: "b", FileName: "b.txt", Content: []byte("B")}, nil),
+			3: td.Struct(httpmock.MultipartPart{Content: []byte("C")},
+				td.StructFields{
+					"FileName": "",
+					"Header": td.Code(func(h textproto.MIMEHeader) bool {
+						return h.Get("Content-Disposition") == "inline"
+					}),
+				}),
+		}))
+	}
+}
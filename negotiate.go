@@ -0,0 +1,166 @@
+package httpmock
+
+import (
+	"encoding"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// negotiated is a representation of a [NewNegotiatedResponder] body.
+type negotiated struct {
+	mediaType string
+	responder Responder
+}
+
+// mediaRangeQuality returns the quality of mediaType according to
+// accept items. The most specific matching media range wins, as
+// described in RFC 9110 section 12.5.1. 0 is returned if no media
+// range matches.
+func mediaRangeQuality(accept []qualityValue, mediaType string) float64 {
+	typ := mediaType
+	if i := strings.IndexByte(mediaType, '/'); i >= 0 {
+		typ = mediaType[:i]
+	}
+	quality, specificity := 0.0, -1
+	for _, item := range accept {
+		var s int
+		switch item.value {
+		case mediaType:
+			s = 2
+		case typ + "/*":
+			s = 1
+		case "*/*", "*":
+			s = 0
+		default:
+			continue
+		}
+		if s > specificity {
+			quality, specificity = item.q, s
+		}
+	}
+	return quality
+}
+
+// textBody returns the plain text representation of body.
+func textBody(body any) (string, error) {
+	switch b := body.(type) {
+	case string:
+		return b, nil
+	case []byte:
+		return string(b), nil
+	case encoding.TextMarshaler:
+		text, err := b.MarshalText()
+		return string(text), err
+	case fmt.Stringer:
+		return b.String(), nil
+	}
+	return fmt.Sprint(body), nil
+}
+
+// NewNegotiatedResponder creates a [Responder] serving body encoded
+// as JSON, XML or plain text depending on the request Accept header,
+// as described in RFC 9110 section 12.5.1. status is the HTTP status
+// code of the responses.
+//
+// The q-values of the Accept header are honored, the most specific
+// media range having precedence over wildcard ones ("text/*" or
+// "*/*"). The available media types are, in the server preference
+// order used to break ties:
+//   - application/json, encoded as [NewJsonResponse] does;
+//   - application/xml and text/xml, encoded as [NewXmlResponse] does;
+//   - text/plain, using body as is if it is a string or a []byte, its
+//     MarshalText method if it implements [encoding.TextMarshaler],
+//     its String method if it implements [fmt.Stringer] or
+//     [fmt.Sprint] otherwise.
+//
+// A request without Accept header gets JSON. If no media type is
+// acceptable, a 406 Not Acceptable response listing the available
+// ones is returned. All responses contain a "Vary: Accept" header.
+//
+// An error is returned if body cannot be encoded in JSON. If body
+// cannot be encoded in XML (as maps for example), XML media types
+// are just not available.
+//
+//	responder, err := httpmock.NewNegotiatedResponder(200, &MyBody)
+//	if err != nil {
+//	  return err
+//	}
+//	httpmock.RegisterResponder("GET", "/test/path", responder)
+func NewNegotiatedResponder(status int, body any) (Responder, error) {
+	var (
+		offers    []negotiated
+		available []string
+	)
+	offer := func(mediaType, contentType string, resp *http.Response) {
+		resp.Header.Set("Content-Type", contentType)
+		resp.Header.Set("Vary", "Accept")
+		offers = append(offers, negotiated{
+			mediaType: mediaType,
+			responder: ResponderFromResponse(resp),
+		})
+		available = append(available, mediaType)
+	}
+
+	resp, err := NewJsonResponse(status, body)
+	if err != nil {
+		return nil, err
+	}
+	offer("application/json", "application/json", resp)
+
+	if resp, err := NewXmlResponse(status, body); err == nil {
+		offer("application/xml", "application/xml", resp)
+		resp, _ = NewXmlResponse(status, body)
+		offer("text/xml", "text/xml", resp)
+	}
+
+	text, err := textBody(body)
+	if err != nil {
+		return nil, err
+	}
+	offer("text/plain", "text/plain; charset=utf-8", NewStringResponse(status, text))
+
+	return func(req *http.Request) (*http.Response, error) {
+		accept := req.Header["Accept"]
+		if len(accept) == 0 {
+			return offers[0].responder(req)
+		}
+
+		items := parseQualityValues(strings.Join(accept, ","))
+		best, bestQ := -1, 0.0
+		for i, offer := range offers {
+			if q := mediaRangeQuality(items, offer.mediaType); q > bestQ {
+				best, bestQ = i, q
+			}
+		}
+		if best < 0 {
+			resp := NewStringResponse(http.StatusNotAcceptable,
+				"Not Acceptable, available media types: "+strings.Join(available, ", "))
+			resp.Header.Set("Content-Type", "text/plain; charset=utf-8")
+			resp.Header.Set("Vary", "Accept")
+			resp.Request = req
+			return resp, nil
+		}
+		return offers[best].responder(req)
+	}, nil
+}
+
+// NewNegotiatedResponderOrPanic is like [NewNegotiatedResponder] but
+// panics in case of error.
+//
+// It simplifies the call of [RegisterResponder], avoiding the use of a
+// temporary variable and an error check, and so can be used as
+// [NewStringResponder] or [NewBytesResponder] in such context:
+//
+//	httpmock.RegisterResponder(
+//	  "GET",
+//	  "/test/path",
+//	  httpmock.NewNegotiatedResponderOrPanic(200, &MyBody),
+//	)
+func NewNegotiatedResponderOrPanic(status int, body any) Responder {
+	responder, err := NewNegotiatedResponder(status, body)
+	if err != nil {
+		panic(err)
+	}
+	return responder
+}
@@ -0,0 +1,88 @@
+package httpmock_test
+
+import (
+	"encoding/xml"
+	"net/http"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/td"
+
+	"github.com/jarcoal/httpmock"
+)
+
+type negotiatedBody struct {
+	XMLName xml.Name `json:"-" xml:"user"`
+	Name    string   `json:"name" xml:"name"`
+}
+
+func (b negotiatedBody) String() string {
+	return "user " + b.Name
+}
+
+func TestNewNegotiatedResponder(t *testing.T) {
+	assert, require := td.AssertRequire(t)
+
+	responder := httpmock.NewNegotiatedResponderOrPanic(200, negotiatedBody{Name: "Bob"})
+
+	const (
+		jsonBody = `{"name":"Bob"}`
+		xmlBody  = `<user><name>Bob</name></user>`
+		textBody = `user Bob`
+
+		notAcceptable = "Not Acceptable, available media types: application/json, application/xml, text/xml, text/plain"
+	)
+
+	for _, tc := range []struct {
+		accept      []string
+		status      int
+		contentType string
+		body        string
+	}{
+		{nil, 200, "application/json", jsonBody},
+		{[]string{"application/json"}, 200, "application/json", jsonBody},
+		{[]string{"application/xml"}, 200, "application/xml", xmlBody},
+		{[]string{"text/xml"}, 200, "text/xml", xmlBody},
+		{[]string{"text/plain"}, 200, "text/plain; charset=utf-8", textBody},
+		{[]string{"*/*"}, 200, "application/json", jsonBody},
+		{[]string{"text/*"}, 200, "text/xml", xmlBody},
+		{[]string{"text/*, text/xml;q=0"}, 200, "text/plain; charset=utf-8", textBody},
+		{[]string{"application/json;q=0.5, application/xml;q=0.9"}, 200, "application/xml", xmlBody},
+		{[]string{"text/html", "application/*;q=0.2, */*;q=0.1"}, 200, "application/json", jsonBody},
+		{[]string{"*/*;q=0.1, application/json;q=0"}, 200, "application/xml", xmlBody},
+		{[]string{"TEXT/Plain; charset=utf-8"}, 200, "text/plain; charset=utf-8", textBody},
+		{[]string{"text/html, image/png"}, 406, "text/plain; charset=utf-8", notAcceptable},
+		{[]string{"application/json;q=0"}, 406, "text/plain; charset=utf-8", notAcceptable},
+	} {
+		req, err := http.NewRequest("GET", "http://z.tld/", nil)
+		require.CmpNoError(err)
+		req.Header["Accept"] = tc.accept
+
+		resp, err := responder(req)
+		require.CmpNoError(err)
+		assert.Cmp(resp.StatusCode, tc.status, tc.accept)
+		assert.Cmp(resp.Header.Get("Content-Type"), tc.contentType, tc.accept)
+		assert.Cmp(resp.Header.Get("Vary"), "Accept", tc.accept)
+		assertBody(assert, resp, tc.body)
+	}
+
+	// Maps cannot be encoded in XML
+	responder = httpmock.NewNegotiatedResponderOrPanic(200, map[string]int{"a": 1})
+	req, err := http.NewRequest("GET", "http://z.tld/", nil)
+	require.CmpNoError(err)
+
+	req.Header.Set("Accept", "application/xml")
+	resp, err := responder(req)
+	require.CmpNoError(err)
+	assert.Cmp(resp.StatusCode, http.StatusNotAcceptable)
+	assertBody(assert, resp, "Not Acceptable, available media types: application/json, text/plain")
+
+	req.Header.Set("Accept", "text/plain")
+	resp, err = responder(req)
+	require.CmpNoError(err)
+	assertBody(assert, resp, "map[a:1]")
+
+	// JSON encoding error
+	_, err = httpmock.NewNegotiatedResponder(200, func() {})
+	assert.CmpError(err)
+	assert.CmpPanic(func() { httpmock.NewNegotiatedResponderOrPanic(200, func() {}) }, td.NotNil())
+}
@@ -0,0 +1,180 @@
+package httpmock
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"syscall"
+)
+
+// NetErrorKind is the kind of a network error built by [NewNetError].
+type NetErrorKind int
+
+const (
+	// NetErrConnRefused is a [*net.OpError] wrapping
+	// [syscall.ECONNREFUSED], as returned when dialing a host that
+	// does not listen on the port:
+	//	dial tcp 192.0.2.1:80: connect: connection refused
+	NetErrConnRefused NetErrorKind = iota
+	// NetErrConnReset is a [*net.OpError] wrapping
+	// [syscall.ECONNRESET], as returned when the server abruptly
+	// closes the connection:
+	//	read tcp 192.0.2.100:54321->192.0.2.1:80: read: connection reset by peer
+	NetErrConnReset
+	// NetErrDNSNotFound is a [*net.OpError] wrapping a
+	// [*net.DNSError] with IsNotFound set:
+	//	dial tcp: lookup z.tld: no such host
+	NetErrDNSNotFound
+	// NetErrDNSTimeout is a [*net.OpError] wrapping a [*net.DNSError]
+	// with IsTimeout and IsTemporary set:
+	//	dial tcp: lookup z.tld: i/o timeout
+	NetErrDNSTimeout
+	// NetErrDialTimeout is a [*net.OpError] wrapping
+	// [os.ErrDeadlineExceeded] (since go1.15), so its Timeout method
+	// returns true:
+	//	dial tcp 192.0.2.1:80: i/o timeout
+	NetErrDialTimeout
+	// NetErrReadTimeout is a [*net.OpError] wrapping
+	// [os.ErrDeadlineExceeded] (since go1.15), so its Timeout method
+	// returns true:
+	//	read tcp 192.0.2.100:54321->192.0.2.1:80: i/o timeout
+	NetErrReadTimeout
+	// NetErrResponseHeaderTimeout is a [net.Error] whose Timeout
+	// method returns true, as returned when
+	// [http.Transport.ResponseHeaderTimeout] expires:
+	//	net/http: timeout awaiting response headers
+	NetErrResponseHeaderTimeout
+	// NetErrTLSHandshakeTimeout is a [net.Error] whose Timeout method
+	// returns true, as returned when
+	// [http.Transport.TLSHandshakeTimeout] expires:
+	//	net/http: TLS handshake timeout
+	NetErrTLSHandshakeTimeout
+	// NetErrUnexpectedEOF is [io.ErrUnexpectedEOF], as returned when
+	// the connection is closed before the end of the response
+	// headers.
+	NetErrUnexpectedEOF
+	// NetErrTLSUnknownAuthority is a [x509.UnknownAuthorityError],
+	// wrapped in a [*tls.CertificateVerificationError] since go1.20, as
+	// returned when the server certificate is not trusted:
+	//	tls: failed to verify certificate: x509: certificate signed by unknown authority
+	NetErrTLSUnknownAuthority
+	// NetErrTLSHostname is a [x509.HostnameError], wrapped in a
+	// [*tls.CertificateVerificationError] since go1.20, as returned
+	// when the server certificate does not match the host:
+	//	tls: failed to verify certificate: x509: certificate is valid for other.z.tld, not z.tld
+	NetErrTLSHostname
+	// NetErrTLSRecordHeader is a [tls.RecordHeaderError], as returned
+	// when the server does not speak TLS:
+	//	tls: first record does not look like a TLS handshake
+	NetErrTLSRecordHeader
+)
+
+// httpTimeoutError mimics the net/http internal timeout errors.
+type httpTimeoutError struct {
+	msg string
+}
+
+func (e *httpTimeoutError) Error() string   { return e.msg }
+func (e *httpTimeoutError) Timeout() bool   { return true }
+func (e *httpTimeoutError) Temporary() bool { return true }
+
+// netAddrs returns the fake local and remote TCP addresses of a
+// connection to req host. The remote IP is the host one if it is an
+// IP, 192.0.2.1 otherwise.
+func netAddrs(req *http.Request) (local, remote *net.TCPAddr) {
+	host, port := req.URL.Hostname(), req.URL.Port()
+	ip := net.ParseIP(host)
+	if ip == nil {
+		ip = net.IPv4(192, 0, 2, 1)
+	}
+	p, err := strconv.Atoi(port)
+	if err != nil {
+		p = 80
+		if req.URL.Scheme == "https" {
+			p = 443
+		}
+	}
+	return &net.TCPAddr{IP: net.IPv4(192, 0, 2, 100), Port: 54321},
+		&net.TCPAddr{IP: ip, Port: p}
+}
+
+// NewNetError returns an error of kind, typed and wrapped as
+// [http.Transport] does, for req. The host of req is used in DNS and
+// TLS errors and, if it is an IP, as remote address.
+//
+// Note that [http.Client] wraps errors returned by its transport in
+// a [*url.Error].
+//
+//	_, err := client.Do(req) // with NewNetErrorResponder(httpmock.NetErrConnReset)
+//	errors.Is(err, syscall.ECONNRESET) // true
+//	var opErr *net.OpError
+//	errors.As(err, &opErr) // true
+//
+// A panic occurs if kind is unknown.
+func NewNetError(kind NetErrorKind, req *http.Request) error {
+	local, remote := netAddrs(req)
+	switch kind {
+	case NetErrConnRefused:
+		return &net.OpError{
+			Op: "dial", Net: "tcp", Addr: remote,
+			Err: os.NewSyscallError("connect", syscall.ECONNREFUSED),
+		}
+	case NetErrConnReset:
+		return &net.OpError{
+			Op: "read", Net: "tcp", Source: local, Addr: remote,
+			Err: os.NewSyscallError("read", syscall.ECONNRESET),
+		}
+	case NetErrDNSNotFound:
+		return &net.OpError{
+			Op: "dial", Net: "tcp",
+			Err: &net.DNSError{Err: "no such host", Name: req.URL.Hostname(), IsNotFound: true},
+		}
+	case NetErrDNSTimeout:
+		return &net.OpError{
+			Op: "dial", Net: "tcp",
+			Err: &net.DNSError{Err: "i/o timeout", Name: req.URL.Hostname(), IsTimeout: true, IsTemporary: true},
+		}
+	case NetErrDialTimeout:
+		return &net.OpError{Op: "dial", Net: "tcp", Addr: remote, Err: errDeadlineExceeded}
+	case NetErrReadTimeout:
+		return &net.OpError{Op: "read", Net: "tcp", Source: local, Addr: remote, Err: errDeadlineExceeded}
+	case NetErrResponseHeaderTimeout:
+		return &httpTimeoutError{msg: "net/http: timeout awaiting response headers"}
+	case NetErrTLSHandshakeTimeout:
+		return &httpTimeoutError{msg: "net/http: TLS handshake timeout"}
+	case NetErrUnexpectedEOF:
+		return io.ErrUnexpectedEOF
+	case NetErrTLSUnknownAuthority:
+		return certificateError(nil, x509.UnknownAuthorityError{})
+	case NetErrTLSHostname:
+		cert := &x509.Certificate{DNSNames: []string{"other." + req.URL.Hostname()}}
+		return certificateError(cert, x509.HostnameError{Certificate: cert, Host: req.URL.Hostname()})
+	case NetErrTLSRecordHeader:
+		return tls.RecordHeaderError{Msg: "first record does not look like a TLS handshake"}
+	}
+	panic(fmt.Sprintf("NewNetError: unknown kind %d", kind))
+}
+
+// NewNetErrorResponder creates a [Responder] that returns no response
+// and the error of kind built by [NewNetError] for each request.
+//
+//	httpmock.RegisterResponder("GET", "http://z.tld/",
+//	  httpmock.NewNetErrorResponder(httpmock.NetErrConnRefused))
+//
+// Combined with [Responder.Then], it is handy to test retry logics:
+//
+//	httpmock.RegisterResponder("GET", "http://z.tld/",
+//	  httpmock.NewNetErrorResponder(httpmock.NetErrReadTimeout).Once().
+//	    Then(httpmock.NewStringResponder(200, "OK")))
+func NewNetErrorResponder(kind NetErrorKind) Responder {
+	NewNetError(kind, &http.Request{URL: &url.URL{}}) // panics early if kind is unknown
+	return func(req *http.Request) (*http.Response, error) {
+		return nil, NewNetError(kind, req)
+	}
+}
@@ -0,0 +1,9 @@
+//go:build go1.15
+// +build go1.15
+
+package httpmock
+
+import "os"
+
+// errDeadlineExceeded is the error wrapped by net timeout errors.
+var errDeadlineExceeded error = os.ErrDeadlineExceeded
@@ -0,0 +1,13 @@
+//go:build go1.15
+// +build go1.15
+
+package httpmock_test
+
+import (
+	"errors"
+	"os"
+)
+
+func isDeadlineExceeded(err error) bool {
+	return errors.Is(err, os.ErrDeadlineExceeded)
+}
@@ -0,0 +1,19 @@
+//go:build go1.20
+// +build go1.20
+
+package httpmock
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+)
+
+// certificateError wraps err in a [*tls.CertificateVerificationError],
+// as [http.Transport] does since go1.20.
+func certificateError(cert *x509.Certificate, err error) error {
+	cve := &tls.CertificateVerificationError{Err: err}
+	if cert != nil {
+		cve.UnverifiedCertificates = []*x509.Certificate{cert}
+	}
+	return cve
+}
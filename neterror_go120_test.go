@@ -0,0 +1,16 @@
+//go:build go1.20
+// +build go1.20
+
+package httpmock_test
+
+import (
+	"crypto/tls"
+	"errors"
+)
+
+const certificateErrorPrefix = "tls: failed to verify certificate: "
+
+func isCertificateError(err error) bool {
+	var cve *tls.CertificateVerificationError
+	return errors.As(err, &cve)
+}
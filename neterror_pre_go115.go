@@ -0,0 +1,8 @@
+//go:build !go1.15
+// +build !go1.15
+
+package httpmock
+
+// errDeadlineExceeded is the error wrapped by net timeout errors, as
+// the internal poll.TimeoutError before go1.15.
+var errDeadlineExceeded error = &httpTimeoutError{msg: "i/o timeout"}
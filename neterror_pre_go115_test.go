@@ -0,0 +1,10 @@
+//go:build !go1.15
+// +build !go1.15
+
+package httpmock_test
+
+// os.ErrDeadlineExceeded does not exist before go1.15, Timeout method
+// is checked anyway.
+func isDeadlineExceeded(error) bool {
+	return true
+}
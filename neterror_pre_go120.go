@@ -0,0 +1,12 @@
+//go:build !go1.20
+// +build !go1.20
+
+package httpmock
+
+import "crypto/x509"
+
+// certificateError returns err as is, as [http.Transport] does
+// before go1.20.
+func certificateError(_ *x509.Certificate, err error) error {
+	return err
+}
@@ -0,0 +1,10 @@
+//go:build !go1.20
+// +build !go1.20
+
+package httpmock_test
+
+const certificateErrorPrefix = ""
+
+func isCertificateError(error) bool {
+	return true
+}
@@ -0,0 +1,156 @@
+package httpmock_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/td"
+
+	"github.com/jarcoal/httpmock"
+)
+
+func TestNewNetErrorResponder(t *testing.T) {
+	assert, require := td.AssertRequire(t)
+
+	mt := httpmock.NewMockTransport()
+	client := &http.Client{Transport: mt}
+
+	for _, tc := range []struct {
+		name    string
+		kind    httpmock.NetErrorKind
+		url     string
+		message string
+		timeout bool
+		check   func(err error) bool
+	}{
+		{
+			name:    "conn refused",
+			kind:    httpmock.NetErrConnRefused,
+			url:     "http://z.tld/",
+			message: "dial tcp 192.0.2.1:80: connect: connection refused",
+			check: func(err error) bool {
+				var opErr *net.OpError
+				return errors.Is(err, syscall.ECONNREFUSED) && errors.As(err, &opErr) && opErr.Op == "dial"
+			},
+		},
+		{
+			name:    "conn reset",
+			kind:    httpmock.NetErrConnReset,
+			url:     "https://10.0.0.1:8443/",
+			message: "read tcp 192.0.2.100:54321->10.0.0.1:8443: read: connection reset by peer",
+			check:   func(err error) bool { return errors.Is(err, syscall.ECONNRESET) },
+		},
+		{
+			name:    "DNS not found",
+			kind:    httpmock.NetErrDNSNotFound,
+			url:     "http://z.tld/",
+			message: "dial tcp: lookup z.tld: no such host",
+			check: func(err error) bool {
+				var dnsErr *net.DNSError
+				return errors.As(err, &dnsErr) && dnsErr.IsNotFound && dnsErr.Name == "z.tld"
+			},
+		},
+		{
+			name:    "DNS timeout",
+			kind:    httpmock.NetErrDNSTimeout,
+			url:     "http://z.tld/",
+			message: "dial tcp: lookup z.tld: i/o timeout",
+			timeout: true,
+			check: func(err error) bool {
+				var dnsErr *net.DNSError
+				return errors.As(err, &dnsErr) && dnsErr.IsTimeout
+			},
+		},
+		{
+			name:    "dial timeout",
+			kind:    httpmock.NetErrDialTimeout,
+			url:     "https://z.tld/",
+			message: "dial tcp 192.0.2.1:443: i/o timeout",
+			timeout: true,
+			check:   isDeadlineExceeded,
+		},
+		{
+			name:    "read timeout",
+			kind:    httpmock.NetErrReadTimeout,
+			url:     "http://z.tld/",
+			message: "read tcp 192.0.2.100:54321->192.0.2.1:80: i/o timeout",
+			timeout: true,
+			check:   isDeadlineExceeded,
+		},
+		{
+			name:    "response header timeout",
+			kind:    httpmock.NetErrResponseHeaderTimeout,
+			url:     "http://z.tld/",
+			message: "net/http: timeout awaiting response headers",
+			timeout: true,
+		},
+		{
+			name:    "TLS handshake timeout",
+			kind:    httpmock.NetErrTLSHandshakeTimeout,
+			url:     "https://z.tld/",
+			message: "net/http: TLS handshake timeout",
+			timeout: true,
+		},
+		{
+			name:    "unexpected EOF",
+			kind:    httpmock.NetErrUnexpectedEOF,
+			url:     "http://z.tld/",
+			message: "unexpected EOF",
+			check:   func(err error) bool { return errors.Is(err, io.ErrUnexpectedEOF) },
+		},
+		{
+			name:    "TLS unknown authority",
+			kind:    httpmock.NetErrTLSUnknownAuthority,
+			url:     "https://z.tld/",
+			message: certificateErrorPrefix + "x509: certificate signed by unknown authority",
+			check: func(err error) bool {
+				return isCertificateError(err) && errors.As(err, &x509.UnknownAuthorityError{})
+			},
+		},
+		{
+			name:    "TLS hostname",
+			kind:    httpmock.NetErrTLSHostname,
+			url:     "https://z.tld/",
+			message: certificateErrorPrefix + "x509: certificate is valid for other.z.tld, not z.tld",
+			check: func(err error) bool {
+				return isCertificateError(err) && errors.As(err, &x509.HostnameError{})
+			},
+		},
+		{
+			name:    "TLS record header",
+			kind:    httpmock.NetErrTLSRecordHeader,
+			url:     "https://z.tld/",
+			message: "tls: first record does not look like a TLS handshake",
+			check: func(err error) bool {
+				return errors.As(err, &tls.RecordHeaderError{})
+			},
+		},
+	} {
+		mt.RegisterResponder("GET", tc.url, httpmock.NewNetErrorResponder(tc.kind))
+
+		_, err := client.Get(tc.url)
+		require.NotNil(err, tc.name)
+
+		var urlErr *url.Error
+		if assert.True(errors.As(err, &urlErr), tc.name) {
+			assert.String(urlErr.Err, tc.message, tc.name)
+		}
+
+		var netErr net.Error
+		assert.Cmp(errors.As(err, &netErr) && netErr.Timeout(), tc.timeout, tc.name)
+
+		if tc.check != nil {
+			assert.True(tc.check(err), tc.name)
+		}
+	}
+
+	assert.CmpPanic(func() { httpmock.NewNetErrorResponder(1000) },
+		"NewNetError: unknown kind 1000")
+}
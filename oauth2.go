@@ -0,0 +1,445 @@
+package httpmock
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OAuth2Provider is a fake OAuth2 authorization server and OpenID
+// Connect provider. Once created by [NewOAuth2Provider], register its
+// responders using [MockTransport.RegisterOAuth2Provider] or
+// [RegisterOAuth2Provider]. The following endpoints are then
+// available under Issuer URL:
+//   - GET /.well-known/openid-configuration: the discovery document;
+//   - GET /.well-known/jwks.json: the JSON Web Key Set containing the
+//     public key used to sign tokens;
+//   - GET /authorize: automatically grants the authorization to
+//     Subject and redirects to redirect_uri with a new code;
+//   - POST /token: the token endpoint, supporting client_credentials,
+//     refresh_token and authorization_code grant types;
+//   - POST /introspect: the token introspection endpoint (RFC 7662).
+//
+// Clients authenticate using HTTP Basic authentication or
+// client_id and client_secret form fields.
+//
+// Access and ID tokens are JWTs signed with RS256 using a RSA key
+// generated by [NewOAuth2Provider]. Refresh tokens and authorization
+// codes are opaque and single-use.
+//
+// Exported fields can be changed before registering the provider.
+type OAuth2Provider struct {
+	// Issuer is the base URL of the provider, as "https://auth.z.tld".
+	Issuer string
+	// Clients contains the secrets of the known clients, indexed by
+	// client ID.
+	Clients map[string]string
+	// Subject is the "sub" claim of tokens issued for the
+	// authorization_code grant type. Defaults to "user".
+	Subject string
+	// Claims are added to every issued access and ID token. They can
+	// override the standard ones.
+	Claims map[string]any
+	// AccessTokenTTL is the lifetime of access and ID tokens. Defaults
+	// to 1 hour.
+	AccessTokenTTL time.Duration
+	// Clock is used to compute tokens issue and expiration times. If
+	// nil, the clock of the MockTransport (see [MockTransport.SetClock])
+	// is used.
+	Clock Clock
+
+	key   *rsa.PrivateKey
+	keyID string
+
+	mu            sync.Mutex
+	codes         map[string]oauth2Grant
+	refreshTokens map[string]oauth2Grant
+}
+
+// oauth2Grant is what an authorization code or a refresh token
+// grants.
+type oauth2Grant struct {
+	clientID    string
+	subject     string
+	scope       string
+	redirectURI string
+	nonce       string
+}
+
+// NewOAuth2Provider returns a new [*OAuth2Provider] at issuer base
+// URL, knowing clients, a map of client secrets indexed by client ID.
+// A new RSA key is generated to sign the tokens.
+func NewOAuth2Provider(issuer string, clients map[string]string) *OAuth2Provider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic("NewOAuth2Provider cannot generate RSA key: " + err.Error())
+	}
+	return &OAuth2Provider{
+		Issuer:         strings.TrimSuffix(issuer, "/"),
+		Clients:        clients,
+		Subject:        "user",
+		AccessTokenTTL: time.Hour,
+		key:            key,
+		keyID:          randomHex(8),
+		codes:          map[string]oauth2Grant{},
+		refreshTokens:  map[string]oauth2Grant{},
+	}
+}
+
+func (p *OAuth2Provider) clock(req *http.Request) Clock {
+	if p.Clock != nil {
+		return p.Clock
+	}
+	if req != nil {
+		return clockOf(req)
+	}
+	return SystemClock
+}
+
+var b64url = base64.RawURLEncoding
+
+// sign returns a JWT containing claims, signed with RS256.
+func (p *OAuth2Provider) sign(claims map[string]any) string {
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": p.keyID})
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		panic("OAuth2Provider cannot encode claims: " + err.Error())
+	}
+	signed := b64url.EncodeToString(header) + "." + b64url.EncodeToString(payload)
+	h := sha256.Sum256([]byte(signed))
+	sig, _ := rsa.SignPKCS1v15(rand.Reader, p.key, crypto.SHA256, h[:]) // cannot fail
+	return signed + "." + b64url.EncodeToString(sig)
+}
+
+// NewToken returns a new signed access token for subject with scope,
+// issued by p now according to p.Clock. extra claims are added to
+// the token, after p.Claims.
+//
+// It is useful to get a valid token without going through the token
+// endpoint.
+func (p *OAuth2Provider) NewToken(subject, scope string, extra map[string]any) string {
+	return p.newToken(nil, subject, "", scope, extra)
+}
+
+func (p *OAuth2Provider) newToken(req *http.Request, subject, clientID, scope string, extra map[string]any) string {
+	now := p.clock(req).Now()
+	claims := map[string]any{
+		"iss": p.Issuer,
+		"sub": subject,
+		"iat": now.Unix(),
+		"nbf": now.Unix(),
+		"exp": now.Add(p.AccessTokenTTL).Unix(),
+		"jti": randomHex(16),
+	}
+	if clientID != "" {
+		claims["aud"] = clientID
+		claims["client_id"] = clientID
+	}
+	if scope != "" {
+		claims["scope"] = scope
+	}
+	for k, v := range p.Claims {
+		claims[k] = v
+	}
+	for k, v := range extra {
+		claims[k] = v
+	}
+	return p.sign(claims)
+}
+
+// ParseToken checks token signature and validity period according to
+// p.Clock, then returns its claims. It allows a mocked service to
+// check the tokens it receives.
+func (p *OAuth2Provider) ParseToken(token string) (map[string]any, error) {
+	return p.parseToken(nil, token)
+}
+
+func (p *OAuth2Provider) parseToken(req *http.Request, token string) (map[string]any, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+	sig, err := b64url.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.New("malformed token signature")
+	}
+	h := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if rsa.VerifyPKCS1v15(&p.key.PublicKey, crypto.SHA256, h[:], sig) != nil {
+		return nil, errors.New("invalid token signature")
+	}
+
+	payload, err := b64url.DecodeString(parts[1])
+	if err != nil {
+		return nil, errors.New("malformed token payload")
+	}
+	var claims map[string]any
+	dec := json.NewDecoder(strings.NewReader(string(payload)))
+	dec.UseNumber()
+	if err := dec.Decode(&claims); err != nil {
+		return nil, errors.New("malformed token payload")
+	}
+
+	now := p.clock(req).Now().Unix()
+	if exp, ok := claims["exp"].(json.Number); ok {
+		if e, err := exp.Int64(); err != nil || now >= e {
+			return nil, errors.New("token expired")
+		}
+	}
+	if nbf, ok := claims["nbf"].(json.Number); ok {
+		if n, err := nbf.Int64(); err != nil || now < n {
+			return nil, errors.New("token not yet valid")
+		}
+	}
+	return claims, nil
+}
+
+func oauth2Error(req *http.Request, status int, code, description string) *http.Response {
+	resp, _ := NewJsonResponse(status, map[string]string{ // cannot fail
+		"error":             code,
+		"error_description": description,
+	})
+	resp.Header.Set("Cache-Control", "no-store")
+	if status == http.StatusUnauthorized {
+		resp.Header.Set("WWW-Authenticate", `Basic realm="oauth2"`)
+	}
+	resp.Request = req
+	return resp
+}
+
+// client returns the authenticated client ID of req, or "" if the
+// client is unknown or its secret is wrong.
+func (p *OAuth2Provider) client(req *http.Request, form url.Values) string {
+	id, secret, ok := req.BasicAuth()
+	if ok {
+		id, _ = url.QueryUnescape(id)
+		secret, _ = url.QueryUnescape(secret)
+	} else {
+		id, secret = form.Get("client_id"), form.Get("client_secret")
+	}
+	if expected, ok := p.Clients[id]; ok && id != "" && expected == secret {
+		return id
+	}
+	return ""
+}
+
+func (p *OAuth2Provider) discovery(req *http.Request) (*http.Response, error) {
+	return NewJsonResponse(http.StatusOK, map[string]any{
+		"issuer":                                p.Issuer,
+		"authorization_endpoint":                p.Issuer + "/authorize",
+		"token_endpoint":                        p.Issuer + "/token",
+		"introspection_endpoint":                p.Issuer + "/introspect",
+		"jwks_uri":                              p.Issuer + "/.well-known/jwks.json",
+		"response_types_supported":              []string{"code"},
+		"grant_types_supported":                 []string{"authorization_code", "client_credentials", "refresh_token"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"token_endpoint_auth_methods_supported": []string{"client_secret_basic", "client_secret_post"},
+	})
+}
+
+func (p *OAuth2Provider) jwks(req *http.Request) (*http.Response, error) {
+	pub := p.key.PublicKey
+	return NewJsonResponse(http.StatusOK, map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"use": "sig",
+			"alg": "RS256",
+			"kid": p.keyID,
+			"n":   b64url.EncodeToString(pub.N.Bytes()),
+			"e":   b64url.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	})
+}
+
+func (p *OAuth2Provider) authorize(req *http.Request) (*http.Response, error) {
+	q := req.URL.Query()
+	if _, ok := p.Clients[q.Get("client_id")]; !ok {
+		return oauth2Error(req, http.StatusBadRequest, "unauthorized_client", "unknown client_id"), nil
+	}
+	redirect, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil || !redirect.IsAbs() {
+		return oauth2Error(req, http.StatusBadRequest, "invalid_request", "bad redirect_uri"), nil
+	}
+
+	rq := redirect.Query()
+	if q.Get("response_type") != "code" {
+		rq.Set("error", "unsupported_response_type")
+	} else {
+		code := randomHex(16)
+		p.mu.Lock()
+		p.codes[code] = oauth2Grant{
+			clientID:    q.Get("client_id"),
+			subject:     p.Subject,
+			scope:       q.Get("scope"),
+			redirectURI: q.Get("redirect_uri"),
+			nonce:       q.Get("nonce"),
+		}
+		p.mu.Unlock()
+		rq.Set("code", code)
+	}
+	if state := q.Get("state"); state != "" {
+		rq.Set("state", state)
+	}
+	redirect.RawQuery = rq.Encode()
+
+	resp := NewStringResponse(http.StatusFound, "")
+	resp.Header.Set("Location", redirect.String())
+	resp.Request = req
+	return resp, nil
+}
+
+func (p *OAuth2Provider) token(req *http.Request) (*http.Response, error) {
+	if err := req.ParseForm(); err != nil {
+		return oauth2Error(req, http.StatusBadRequest, "invalid_request", err.Error()), nil
+	}
+	clientID := p.client(req, req.PostForm)
+	if clientID == "" {
+		return oauth2Error(req, http.StatusUnauthorized, "invalid_client", "client authentication failed"), nil
+	}
+
+	var grant oauth2Grant
+	grantType := req.PostForm.Get("grant_type")
+	switch grantType {
+	case "client_credentials":
+		grant = oauth2Grant{
+			clientID: clientID,
+			subject:  clientID,
+			scope:    req.PostForm.Get("scope"),
+		}
+
+	case "authorization_code":
+		p.mu.Lock()
+		code := req.PostForm.Get("code")
+		g, ok := p.codes[code]
+		delete(p.codes, code)
+		p.mu.Unlock()
+		if !ok || g.clientID != clientID || g.redirectURI != req.PostForm.Get("redirect_uri") {
+			return oauth2Error(req, http.StatusBadRequest, "invalid_grant", "invalid authorization code"), nil
+		}
+		grant = g
+
+	case "refresh_token":
+		scope := req.PostForm.Get("scope")
+		p.mu.Lock()
+		rt := req.PostForm.Get("refresh_token")
+		g, ok := p.refreshTokens[rt]
+		ok = ok && g.clientID == clientID
+		scopeOK := scopeIncluded(scope, g.scope)
+		if ok && scopeOK {
+			delete(p.refreshTokens, rt)
+		}
+		p.mu.Unlock()
+		if !ok {
+			return oauth2Error(req, http.StatusBadRequest, "invalid_grant", "invalid refresh token"), nil
+		}
+		// RFC 6749 section 6: the scope cannot be widened
+		if !scopeOK {
+			return oauth2Error(req, http.StatusBadRequest, "invalid_scope",
+				"requested scope exceeds the originally granted one"), nil
+		}
+		grant = g
+		grant.nonce = ""
+		if scope != "" {
+			grant.scope = scope
+		}
+
+	default:
+		return oauth2Error(req, http.StatusBadRequest, "unsupported_grant_type",
+			"grant_type must be client_credentials, authorization_code or refresh_token"), nil
+	}
+
+	body := map[string]any{
+		"access_token": p.newToken(req, grant.subject, grant.clientID, grant.scope, nil),
+		"token_type":   "Bearer",
+		"expires_in":   int64(p.AccessTokenTTL / time.Second),
+	}
+	if grant.scope != "" {
+		body["scope"] = grant.scope
+	}
+
+	if grantType != "client_credentials" {
+		rt := randomHex(16)
+		p.mu.Lock()
+		p.refreshTokens[rt] = grant
+		p.mu.Unlock()
+		body["refresh_token"] = rt
+
+		if scopeContains(grant.scope, "openid") {
+			extra := map[string]any{"azp": grant.clientID}
+			if grant.nonce != "" {
+				extra["nonce"] = grant.nonce
+			}
+			body["id_token"] = p.newToken(req, grant.subject, grant.clientID, "", extra)
+		}
+	}
+
+	resp, err := NewJsonResponse(http.StatusOK, body)
+	if err != nil {
+		return nil, err
+	}
+	resp.Header.Set("Cache-Control", "no-store")
+	return resp, nil
+}
+
+// scopeIncluded returns true if all tokens of scope are in granted.
+func scopeIncluded(scope, granted string) bool {
+	for _, s := range strings.Fields(scope) {
+		if !scopeContains(granted, s) {
+			return false
+		}
+	}
+	return true
+}
+
+func scopeContains(scope, s string) bool {
+	for _, cur := range strings.Fields(scope) {
+		if cur == s {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *OAuth2Provider) introspect(req *http.Request) (*http.Response, error) {
+	if err := req.ParseForm(); err != nil {
+		return oauth2Error(req, http.StatusBadRequest, "invalid_request", err.Error()), nil
+	}
+	if p.client(req, req.PostForm) == "" {
+		return oauth2Error(req, http.StatusUnauthorized, "invalid_client", "client authentication failed"), nil
+	}
+
+	token := req.PostForm.Get("token")
+	body := map[string]any{"active": false}
+
+	if claims, err := p.parseToken(req, token); err == nil {
+		body = claims
+		body["active"] = true
+		body["token_type"] = "Bearer"
+	} else {
+		p.mu.Lock()
+		g, ok := p.refreshTokens[token]
+		p.mu.Unlock()
+		if ok {
+			body = map[string]any{
+				"active":     true,
+				"token_type": "refresh_token",
+				"sub":        g.subject,
+				"client_id":  g.clientID,
+			}
+			if g.scope != "" {
+				body["scope"] = g.scope
+			}
+		}
+	}
+	return NewJsonResponse(http.StatusOK, body)
+}
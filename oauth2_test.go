@@ -0,0 +1,324 @@
+package httpmock_test
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+
+	"github.com/jarcoal/httpmock"
+)
+
+func decodeJSONResponse(t testing.TB, resp *http.Response) map[string]interface{} {
+	t.Helper()
+	defer resp.Body.Close()
+	var body map[string]interface{}
+	td.Require(t).CmpNoError(json.NewDecoder(resp.Body).Decode(&body))
+	return body
+}
+
+func jwtClaims(t testing.TB, token string) map[string]interface{} {
+	t.Helper()
+	parts := strings.Split(token, ".")
+	td.Require(t).Len(parts, 3)
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	td.Require(t).CmpNoError(err)
+	var claims map[string]interface{}
+	td.Require(t).CmpNoError(json.Unmarshal(payload, &claims))
+	return claims
+}
+
+func TestOAuth2Provider(t *testing.T) {
+	assert, require := td.AssertRequire(t)
+
+	mt := httpmock.NewMockTransport()
+	clock := httpmock.NewVirtualClock(time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC))
+	mt.SetClock(clock)
+	client := &http.Client{
+		Transport: mt,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	provider := httpmock.NewOAuth2Provider("https://auth.z.tld/", map[string]string{
+		"svc": "s3cr3t",
+		"web": "w3b",
+	})
+	provider.Claims = map[string]interface{}{"tenant": "acme"}
+	mt.RegisterOAuth2Provider(provider)
+
+	postForm := func(path string, form url.Values, user, password string) *http.Response {
+		req, err := http.NewRequest("POST", "https://auth.z.tld"+path, strings.NewReader(form.Encode()))
+		require.CmpNoError(err)
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if user != "" {
+			req.SetBasicAuth(user, password)
+		}
+		resp, err := client.Do(req)
+		require.CmpNoError(err)
+		return resp
+	}
+
+	// Discovery
+	resp, err := client.Get("https://auth.z.tld/.well-known/openid-configuration")
+	require.CmpNoError(err)
+	discovery := decodeJSONResponse(t, resp)
+	assert.Cmp(discovery, td.SuperMapOf(map[string]interface{}{
+		"issuer":                 "https://auth.z.tld",
+		"token_endpoint":         "https://auth.z.tld/token",
+		"authorization_endpoint": "https://auth.z.tld/authorize",
+		"introspection_endpoint": "https://auth.z.tld/introspect",
+		"jwks_uri":               "https://auth.z.tld/.well-known/jwks.json",
+	}, nil))
+
+	// JWKS
+	resp, err = client.Get(discovery["jwks_uri"].(string))
+	require.CmpNoError(err)
+	var jwks struct {
+		Keys []struct {
+			Kty, Alg, Kid, N, E string
+		}
+	}
+	require.CmpNoError(json.NewDecoder(resp.Body).Decode(&jwks))
+	require.Len(jwks.Keys, 1)
+	jwk := jwks.Keys[0]
+	assert.Cmp(jwk.Kty, "RSA")
+	assert.Cmp(jwk.Alg, "RS256")
+	n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+	require.CmpNoError(err)
+	e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+	require.CmpNoError(err)
+	pub := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+
+	verify := func(token string) {
+		t.Helper()
+		parts := strings.Split(token, ".")
+		h := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+		require.CmpNoError(err)
+		assert.CmpNoError(rsa.VerifyPKCS1v15(pub, crypto.SHA256, h[:], sig))
+	}
+
+	// client_credentials, using client_secret_post
+	resp = postForm("/token", url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {"svc"},
+		"client_secret": {"s3cr3t"},
+		"scope":         {"read write"},
+	}, "", "")
+	assert.Cmp(resp.StatusCode, 200)
+	assert.Cmp(resp.Header.Get("Cache-Control"), "no-store")
+	tok := decodeJSONResponse(t, resp)
+	assert.Cmp(tok, map[string]interface{}{
+		"access_token": td.Ignore(),
+		"token_type":   "Bearer",
+		"expires_in":   float64(3600),
+		"scope":        "read write",
+	})
+	accessToken := tok["access_token"].(string)
+	verify(accessToken)
+	assert.Cmp(jwtClaims(t, accessToken), td.SuperMapOf(map[string]interface{}{
+		"iss":       "https://auth.z.tld",
+		"sub":       "svc",
+		"aud":       "svc",
+		"client_id": "svc",
+		"scope":     "read write",
+		"tenant":    "acme",
+		"iat":       float64(clock.Now().Unix()),
+		"exp":       float64(clock.Now().Add(time.Hour).Unix()),
+	}, nil))
+
+	// Introspection
+	resp = postForm("/introspect", url.Values{"token": {accessToken}}, "web", "w3b")
+	assert.Cmp(decodeJSONResponse(t, resp), td.SuperMapOf(map[string]interface{}{
+		"active": true,
+		"sub":    "svc",
+		"scope":  "read write",
+	}, nil))
+
+	clock.Advance(time.Hour)
+	resp = postForm("/introspect", url.Values{"token": {accessToken}}, "web", "w3b")
+	assert.Cmp(decodeJSONResponse(t, resp), map[string]interface{}{"active": false})
+
+	resp = postForm("/introspect", url.Values{"token": {"garbage"}}, "web", "w3b")
+	assert.Cmp(decodeJSONResponse(t, resp), map[string]interface{}{"active": false})
+
+	// Bad client
+	resp = postForm("/token", url.Values{"grant_type": {"client_credentials"}}, "svc", "bad")
+	assert.Cmp(resp.StatusCode, 401)
+	assert.Cmp(resp.Header.Get("WWW-Authenticate"), `Basic realm="oauth2"`)
+	assert.Cmp(decodeJSONResponse(t, resp)["error"], "invalid_client")
+
+	resp = postForm("/introspect", url.Values{"token": {accessToken}}, "", "")
+	assert.Cmp(resp.StatusCode, 401)
+
+	// Bad grant type
+	resp = postForm("/token", url.Values{"grant_type": {"password"}}, "svc", "s3cr3t")
+	assert.Cmp(resp.StatusCode, 400)
+	assert.Cmp(decodeJSONResponse(t, resp)["error"], "unsupported_grant_type")
+
+	// authorization_code
+	resp, err = client.Get("https://auth.z.tld/authorize?response_type=code&client_id=web" +
+		"&redirect_uri=https%3A%2F%2Fapp.z.tld%2Fcb%3Fx%3D1&scope=openid+profile&state=xyz&nonce=n0nc3")
+	require.CmpNoError(err)
+	assert.Cmp(resp.StatusCode, http.StatusFound)
+	location, err := url.Parse(resp.Header.Get("Location"))
+	require.CmpNoError(err)
+	assert.Cmp(location.Host, "app.z.tld")
+	assert.Cmp(location.Path, "/cb")
+	assert.Cmp(location.Query().Get("x"), "1")
+	assert.Cmp(location.Query().Get("state"), "xyz")
+	code := location.Query().Get("code")
+	assert.NotEmpty(code)
+
+	// wrong redirect_uri
+	resp = postForm("/token", url.Values{
+		"grant_type":   {"authorization_code"},
+		"code":         {code},
+		"redirect_uri": {"https://evil.tld/cb"},
+	}, "web", "w3b")
+	assert.Cmp(resp.StatusCode, 400)
+	assert.Cmp(decodeJSONResponse(t, resp)["error"], "invalid_grant")
+
+	// code is single use, so get a new one
+	resp, err = client.Get("https://auth.z.tld/authorize?response_type=code&client_id=web" +
+		"&redirect_uri=https%3A%2F%2Fapp.z.tld%2Fcb&scope=openid+profile&nonce=n0nc3")
+	require.CmpNoError(err)
+	location, err = url.Parse(resp.Header.Get("Location"))
+	require.CmpNoError(err)
+	code = location.Query().Get("code")
+
+	resp = postForm("/token", url.Values{
+		"grant_type":   {"authorization_code"},
+		"code":         {code},
+		"redirect_uri": {"https://app.z.tld/cb"},
+	}, "web", "w3b")
+	assert.Cmp(resp.StatusCode, 200)
+	tok = decodeJSONResponse(t, resp)
+	assert.Cmp(tok, td.SuperMapOf(map[string]interface{}{
+		"access_token":  td.NotEmpty(),
+		"refresh_token": td.NotEmpty(),
+		"id_token":      td.NotEmpty(),
+		"scope":         "openid profile",
+	}, nil))
+	verify(tok["id_token"].(string))
+	assert.Cmp(jwtClaims(t, tok["id_token"].(string)), td.SuperMapOf(map[string]interface{}{
+		"sub":   "user",
+		"aud":   "web",
+		"azp":   "web",
+		"nonce": "n0nc3",
+	}, nil))
+
+	// code replay
+	resp = postForm("/token", url.Values{
+		"grant_type":   {"authorization_code"},
+		"code":         {code},
+		"redirect_uri": {"https://app.z.tld/cb"},
+	}, "web", "w3b")
+	assert.Cmp(resp.StatusCode, 400)
+
+	// refresh_token
+	refreshToken := tok["refresh_token"].(string)
+	resp = postForm("/introspect", url.Values{"token": {refreshToken}}, "web", "w3b")
+	assert.Cmp(decodeJSONResponse(t, resp), td.SuperMapOf(map[string]interface{}{
+		"active":     true,
+		"token_type": "refresh_token",
+		"sub":        "user",
+	}, nil))
+
+	resp = postForm("/token", url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+	}, "svc", "s3cr3t")
+	assert.Cmp(resp.StatusCode, 400, "refresh token of another client")
+
+	resp = postForm("/token", url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+		"scope":         {"openid admin"},
+	}, "web", "w3b")
+	assert.Cmp(resp.StatusCode, 400, "scope widened")
+	assert.Cmp(decodeJSONResponse(t, resp), td.SuperMapOf(map[string]interface{}{
+		"error": "invalid_scope",
+	}, nil))
+
+	resp = postForm("/token", url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+	}, "web", "w3b")
+	assert.Cmp(resp.StatusCode, 200)
+	tok = decodeJSONResponse(t, resp)
+	assert.Cmp(tok["refresh_token"], td.All(td.NotEmpty(), td.Not(refreshToken)))
+	assert.Cmp(jwtClaims(t, tok["id_token"].(string)), td.Not(td.ContainsKey("nonce")))
+
+	// scope narrowed
+	resp = postForm("/token", url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {tok["refresh_token"].(string)},
+		"scope":         {"profile"},
+	}, "web", "w3b")
+	assert.Cmp(resp.StatusCode, 200)
+	assert.Cmp(decodeJSONResponse(t, resp), td.SuperMapOf(map[string]interface{}{
+		"scope": "profile",
+	}, nil))
+
+	// rotated
+	resp = postForm("/token", url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+	}, "web", "w3b")
+	assert.Cmp(resp.StatusCode, 400)
+
+	// authorize errors
+	resp, err = client.Get("https://auth.z.tld/authorize?response_type=code&client_id=unknown&redirect_uri=https%3A%2F%2Fapp.z.tld%2Fcb")
+	require.CmpNoError(err)
+	assert.Cmp(resp.StatusCode, 400)
+	resp, err = client.Get("https://auth.z.tld/authorize?response_type=token&client_id=web&redirect_uri=https%3A%2F%2Fapp.z.tld%2Fcb&state=s")
+	require.CmpNoError(err)
+	assert.Cmp(resp.Header.Get("Location"), "https://app.z.tld/cb?error=unsupported_response_type&state=s")
+}
+
+func TestOAuth2ProviderTokens(t *testing.T) {
+	assert, require := td.AssertRequire(t)
+
+	clock := httpmock.NewVirtualClock(time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC))
+	provider := httpmock.NewOAuth2Provider("https://auth.z.tld", nil)
+	provider.Clock = clock
+	provider.AccessTokenTTL = time.Minute
+
+	token := provider.NewToken("bob", "read", map[string]interface{}{"role": "admin"})
+	claims, err := provider.ParseToken(token)
+	require.CmpNoError(err)
+	assert.Cmp(claims, td.SuperMapOf(map[string]interface{}{
+		"sub":   "bob",
+		"scope": "read",
+		"role":  "admin",
+		"exp":   json.Number("1792317660"),
+	}, nil))
+
+	// Payload of another token
+	parts := strings.Split(token, ".")
+	parts[1] = strings.Split(provider.NewToken("alice", "read", nil), ".")[1]
+	_, err = provider.ParseToken(strings.Join(parts, "."))
+	assert.String(err, "invalid token signature")
+	_, err = provider.ParseToken("a.b")
+	assert.String(err, "malformed token")
+
+	clock.Advance(time.Minute)
+	_, err = provider.ParseToken(token)
+	assert.String(err, "token expired")
+
+	other := httpmock.NewOAuth2Provider("https://auth.z.tld", nil)
+	_, err = other.ParseToken(provider.NewToken("bob", "", nil))
+	assert.String(err, "invalid token signature")
+}
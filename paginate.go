@@ -0,0 +1,310 @@
+package httpmock
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// PaginationStyle defines how a client requests a given page of a
+// list endpoint. See [Pagination].
+type PaginationStyle int
+
+const (
+	// PageNumberPagination uses a 1-based page number and a page size,
+	// as in "?page=2&per_page=10".
+	PageNumberPagination PaginationStyle = iota
+	// OffsetLimitPagination uses a 0-based offset and a limit, as in
+	// "?offset=20&limit=10".
+	OffsetLimitPagination
+	// CursorPagination uses an opaque cursor and a limit, as in
+	// "?cursor=b2Zmc2V0OjIw&limit=10". Cursors are returned in
+	// X-Next-Cursor and X-Prev-Cursor headers (and in Link header if
+	// enabled).
+	CursorPagination
+)
+
+// PageInfo describes the page served by a [Responder] returned by
+// [NewPaginatedResponder]. It is passed to [Pagination] Envelope
+// function.
+type PageInfo struct {
+	Total      int    // total number of items
+	Offset     int    // 0-based index of the first item of the page
+	Limit      int    // page size
+	Page       int    // 1-based page number, only for PageNumberPagination
+	NextCursor string // only for CursorPagination, empty if last page
+	PrevCursor string // only for CursorPagination, empty if first page
+	NextURL    string // empty if last page
+	PrevURL    string // empty if first page
+}
+
+// Pagination configures the [Responder] returned by
+// [NewPaginatedResponder]. The zero value is usable and corresponds
+// to a page number pagination ("?page=N&per_page=M") with 10 items
+// per page, a Link header and a X-Total-Count header.
+type Pagination struct {
+	Style PaginationStyle
+	// PageParam is the query parameter name of the page number, the
+	// offset or the cursor depending on Style. Defaults to "page",
+	// "offset" or "cursor".
+	PageParam string
+	// SizeParam is the query parameter name of the page size.
+	// Defaults to "per_page" for PageNumberPagination, "limit"
+	// otherwise.
+	SizeParam string
+	// DefaultSize is the page size when SizeParam is not set in the
+	// request. Defaults to 10.
+	DefaultSize int
+	// MaxSize, if > 0, caps the page size requested by clients.
+	MaxSize int
+	// NoLinkHeader disables the RFC 5988 Link header containing
+	// "first", "prev", "next" and "last" relations ("last" is never
+	// set for CursorPagination).
+	NoLinkHeader bool
+	// TotalCountHeader is the name of the header containing the total
+	// number of items. Defaults to "X-Total-Count". Set it to "-" to
+	// disable it.
+	TotalCountHeader string
+	// Envelope, if non-nil, allows to wrap the page items (a slice of
+	// the same type as the one passed to [NewPaginatedResponder]) in
+	// a custom value before being JSON encoded. If nil, the JSON
+	// body is the page items array.
+	Envelope func(items any, info PageInfo) any
+}
+
+func (p Pagination) params() (pageParam, sizeParam string) {
+	pageParam, sizeParam = p.PageParam, p.SizeParam
+	if pageParam == "" {
+		switch p.Style {
+		case OffsetLimitPagination:
+			pageParam = "offset"
+		case CursorPagination:
+			pageParam = "cursor"
+		default:
+			pageParam = "page"
+		}
+	}
+	if sizeParam == "" {
+		if p.Style == PageNumberPagination {
+			sizeParam = "per_page"
+		} else {
+			sizeParam = "limit"
+		}
+	}
+	return
+}
+
+func encodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("offset:" + strconv.Itoa(offset)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err == nil {
+		if s := strings.TrimPrefix(string(b), "offset:"); len(s) < len(b) {
+			if offset, err := strconv.Atoi(s); err == nil && offset >= 0 {
+				return offset, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("invalid cursor %q", cursor)
+}
+
+// maxInt is the maximum value of an int, as math.MaxInt which needs
+// go1.17.
+const maxInt = int(^uint(0) >> 1)
+
+func positiveQueryInt(query url.Values, param string, def, lowest int) (int, error) {
+	v := query.Get(param)
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < lowest {
+		return 0, fmt.Errorf("invalid %s parameter %q", param, v)
+	}
+	return n, nil
+}
+
+// NewPaginatedResponder creates a [Responder] serving pages of items
+// as JSON, depending on the pagination parameters found in the
+// request query string. items must be a slice or an array, otherwise
+// a panic occurs.
+//
+// The requested page is computed according to p.Style (see
+// [PaginationStyle]). Requesting a page beyond the last one returns
+// an empty JSON array. Invalid pagination parameters (not a number,
+// negative number, zero page size, page or offset out of range or
+// malformed cursor) return a 400 response.
+//
+// Unless disabled in p, the response contains:
+//   - a X-Total-Count header with the total number of items;
+//   - a RFC 5988 Link header with "first", "prev", "next" and
+//     "last" relations, "prev" and "next" being omitted on
+//     respectively the first and last page.
+//
+// For [CursorPagination], X-Next-Cursor and X-Prev-Cursor headers are
+// also set, unless there is respectively no next or previous page.
+//
+//	articles := []Article{...}
+//	httpmock.RegisterResponder("GET", "https://api.mybiz.com/articles",
+//	  httpmock.NewPaginatedResponder(articles, httpmock.Pagination{
+//	    Style:   httpmock.OffsetLimitPagination,
+//	    MaxSize: 100,
+//	  }))
+func NewPaginatedResponder(items any, p Pagination) Responder {
+	all := reflect.ValueOf(items)
+	if all.Kind() != reflect.Slice && all.Kind() != reflect.Array {
+		panic(fmt.Sprintf("NewPaginatedResponder bad items type %T. Only slices and arrays are allowed", items))
+	}
+	if all.Kind() == reflect.Array {
+		s := reflect.MakeSlice(reflect.SliceOf(all.Type().Elem()), all.Len(), all.Len())
+		reflect.Copy(s, all)
+		all = s
+	}
+	if p.DefaultSize <= 0 {
+		p.DefaultSize = 10
+	}
+	pageParam, sizeParam := p.params()
+
+	return func(req *http.Request) (*http.Response, error) {
+		query := req.URL.Query()
+		total := all.Len()
+
+		limit, err := positiveQueryInt(query, sizeParam, p.DefaultSize, 1)
+		if err != nil {
+			return paginationError(req, err), nil
+		}
+		if p.MaxSize > 0 && limit > p.MaxSize {
+			limit = p.MaxSize
+		}
+
+		info := PageInfo{Total: total, Limit: limit}
+		switch p.Style {
+		case OffsetLimitPagination:
+			info.Offset, err = positiveQueryInt(query, pageParam, 0, 0)
+		case CursorPagination:
+			if cursor := query.Get(pageParam); cursor != "" {
+				info.Offset, err = decodeCursor(cursor)
+			}
+		default:
+			info.Page, err = positiveQueryInt(query, pageParam, 1, 1)
+			if err == nil && info.Page > maxInt/limit {
+				err = fmt.Errorf("%s parameter %q out of range", pageParam, query.Get(pageParam))
+			}
+			info.Offset = (info.Page - 1) * limit
+		}
+		if err == nil && info.Offset > maxInt-limit {
+			err = fmt.Errorf("%s parameter %q out of range", pageParam, query.Get(pageParam))
+		}
+		if err != nil {
+			return paginationError(req, err), nil
+		}
+
+		start, end := info.Offset, info.Offset+limit
+		if start > total {
+			start = total
+		}
+		if end > total {
+			end = total
+		}
+
+		pageURL := func(offset int) string {
+			u := *req.URL
+			q := u.Query()
+			switch p.Style {
+			case OffsetLimitPagination:
+				q.Set(pageParam, strconv.Itoa(offset))
+			case CursorPagination:
+				if offset == 0 {
+					q.Del(pageParam)
+				} else {
+					q.Set(pageParam, encodeCursor(offset))
+				}
+			default:
+				q.Set(pageParam, strconv.Itoa(offset/limit+1))
+			}
+			q.Set(sizeParam, strconv.Itoa(limit))
+			u.RawQuery = q.Encode()
+			return u.String()
+		}
+
+		last := 0
+		if total > 0 {
+			last = (total - 1) / limit * limit
+		}
+
+		hasNext := info.Offset+limit < total
+		hasPrev := info.Offset > 0
+		prev := info.Offset - limit
+		if prev < 0 {
+			prev = 0
+		}
+		if prev > last { // beyond the end, previous page is the last one
+			prev = last
+		}
+		if hasNext {
+			info.NextURL = pageURL(info.Offset + limit)
+		}
+		if hasPrev {
+			info.PrevURL = pageURL(prev)
+		}
+		if p.Style == CursorPagination {
+			if hasNext {
+				info.NextCursor = encodeCursor(info.Offset + limit)
+			}
+			if hasPrev {
+				info.PrevCursor = encodeCursor(prev)
+			}
+		}
+
+		var body any = all.Slice(start, end).Interface()
+		if p.Envelope != nil {
+			body = p.Envelope(body, info)
+		}
+		resp, err := NewJsonResponse(http.StatusOK, body)
+		if err != nil {
+			return nil, err
+		}
+
+		if !p.NoLinkHeader {
+			links := []string{fmt.Sprintf(`<%s>; rel="first"`, pageURL(0))}
+			if hasPrev {
+				links = append(links, fmt.Sprintf(`<%s>; rel="prev"`, info.PrevURL))
+			}
+			if hasNext {
+				links = append(links, fmt.Sprintf(`<%s>; rel="next"`, info.NextURL))
+			}
+			if p.Style != CursorPagination {
+				links = append(links, fmt.Sprintf(`<%s>; rel="last"`, pageURL(last)))
+			}
+			resp.Header.Set("Link", strings.Join(links, ", "))
+		}
+		switch p.TotalCountHeader {
+		case "-":
+		case "":
+			resp.Header.Set("X-Total-Count", strconv.Itoa(total))
+		default:
+			resp.Header.Set(p.TotalCountHeader, strconv.Itoa(total))
+		}
+		if info.NextCursor != "" {
+			resp.Header.Set("X-Next-Cursor", info.NextCursor)
+		}
+		if info.PrevCursor != "" {
+			resp.Header.Set("X-Prev-Cursor", info.PrevCursor)
+		}
+
+		resp.Request = req
+		return resp, nil
+	}
+}
+
+func paginationError(req *http.Request, err error) *http.Response {
+	resp := NewStringResponse(http.StatusBadRequest, err.Error())
+	resp.Request = req
+	return resp
+}
@@ -0,0 +1,150 @@
+package httpmock_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/td"
+
+	"github.com/jarcoal/httpmock"
+)
+
+func getPage(t *td.T, responder httpmock.Responder, url string) (*http.Response, []int) {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	td.Require(t).CmpNoError(err)
+
+	resp, err := responder(req)
+	td.Require(t).CmpNoError(err)
+
+	var items []int
+	if resp.StatusCode == http.StatusOK {
+		td.Require(t).CmpNoError(json.NewDecoder(resp.Body).Decode(&items))
+	}
+	return resp, items
+}
+
+func TestNewPaginatedResponder(t *testing.T) {
+	assert := td.Assert(t)
+
+	items := make([]int, 25)
+	for i := range items {
+		items[i] = i + 1
+	}
+
+	assert.Run("page number", func(assert *td.T) {
+		responder := httpmock.NewPaginatedResponder(items, httpmock.Pagination{})
+
+		resp, page := getPage(assert, responder, "http://z.tld/items")
+		assert.Cmp(page, items[:10])
+		assert.Cmp(resp.Header.Get("X-Total-Count"), "25")
+		assert.Cmp(resp.Header.Get("Link"),
+			`<http://z.tld/items?page=1&per_page=10>; rel="first", `+
+				`<http://z.tld/items?page=2&per_page=10>; rel="next", `+
+				`<http://z.tld/items?page=3&per_page=10>; rel="last"`)
+
+		resp, page = getPage(assert, responder, "http://z.tld/items?page=3&per_page=10")
+		assert.Cmp(page, items[20:])
+		assert.Cmp(resp.Header.Get("Link"),
+			`<http://z.tld/items?page=1&per_page=10>; rel="first", `+
+				`<http://z.tld/items?page=2&per_page=10>; rel="prev", `+
+				`<http://z.tld/items?page=3&per_page=10>; rel="last"`)
+
+		_, page = getPage(assert, responder, "http://z.tld/items?page=4")
+		assert.Cmp(page, []int{})
+
+		// Beyond the end, prev is the last page
+		resp, page = getPage(assert, responder, "http://z.tld/items?page=50")
+		assert.Cmp(page, []int{})
+		assert.Cmp(resp.Header.Get("Link"),
+			`<http://z.tld/items?page=1&per_page=10>; rel="first", `+
+				`<http://z.tld/items?page=3&per_page=10>; rel="prev", `+
+				`<http://z.tld/items?page=3&per_page=10>; rel="last"`)
+
+		resp, _ = getPage(assert, responder, "http://z.tld/items?page=0")
+		assert.Cmp(resp.StatusCode, http.StatusBadRequest)
+		assertBody(assert, resp, `invalid page parameter "0"`)
+
+		resp, _ = getPage(assert, responder, "http://z.tld/items?per_page=x")
+		assert.Cmp(resp.StatusCode, http.StatusBadRequest)
+
+		resp, _ = getPage(assert, responder, "http://z.tld/items?page=4611686018427387904&per_page=4")
+		assert.Cmp(resp.StatusCode, http.StatusBadRequest)
+		assertBody(assert, resp, `page parameter "4611686018427387904" out of range`)
+	})
+
+	assert.Run("offset limit", func(assert *td.T) {
+		responder := httpmock.NewPaginatedResponder(items, httpmock.Pagination{
+			Style:            httpmock.OffsetLimitPagination,
+			MaxSize:          5,
+			NoLinkHeader:     true,
+			TotalCountHeader: "X-Count",
+		})
+
+		resp, page := getPage(assert, responder, "http://z.tld/items?offset=3&limit=50")
+		assert.Cmp(page, items[3:8])
+		assert.Cmp(resp.Header, td.Not(td.ContainsKey("Link")))
+		assert.Cmp(resp.Header, td.Not(td.ContainsKey("X-Total-Count")))
+		assert.Cmp(resp.Header.Get("X-Count"), "25")
+
+		resp, _ = getPage(assert, responder, "http://z.tld/items?offset=-1")
+		assert.Cmp(resp.StatusCode, http.StatusBadRequest)
+
+		resp, _ = getPage(assert, responder, "http://z.tld/items?offset=9223372036854775807")
+		assert.Cmp(resp.StatusCode, http.StatusBadRequest)
+		assertBody(assert, resp, `offset parameter "9223372036854775807" out of range`)
+
+		responder = httpmock.NewPaginatedResponder(items, httpmock.Pagination{
+			Style: httpmock.OffsetLimitPagination,
+		})
+		resp, _ = getPage(assert, responder, "http://z.tld/items?offset=9223372036854775800&limit=100")
+		assert.Cmp(resp.StatusCode, http.StatusBadRequest)
+	})
+
+	assert.Run("cursor", func(assert *td.T) {
+		responder := httpmock.NewPaginatedResponder(items, httpmock.Pagination{
+			Style:            httpmock.CursorPagination,
+			DefaultSize:      20,
+			TotalCountHeader: "-",
+		})
+
+		resp, page := getPage(assert, responder, "http://z.tld/items")
+		assert.Cmp(page, items[:20])
+		assert.Cmp(resp.Header, td.Not(td.ContainsKey("X-Total-Count")))
+		assert.Cmp(resp.Header, td.Not(td.ContainsKey("X-Prev-Cursor")))
+		next := resp.Header.Get("X-Next-Cursor")
+		assert.NotEmpty(next)
+		assert.Cmp(resp.Header.Get("Link"),
+			`<http://z.tld/items?limit=20>; rel="first", `+
+				`<http://z.tld/items?cursor=`+next+`&limit=20>; rel="next"`)
+
+		resp, page = getPage(assert, responder, "http://z.tld/items?cursor="+next)
+		assert.Cmp(page, items[20:])
+		assert.Cmp(resp.Header, td.Not(td.ContainsKey("X-Next-Cursor")))
+		assert.NotEmpty(resp.Header.Get("X-Prev-Cursor"))
+
+		resp, _ = getPage(assert, responder, "http://z.tld/items?cursor=bad")
+		assert.Cmp(resp.StatusCode, http.StatusBadRequest)
+	})
+
+	assert.Run("envelope", func(assert *td.T) {
+		responder := httpmock.NewPaginatedResponder([3]string{"a", "b", "c"}, httpmock.Pagination{
+			DefaultSize: 2,
+			Envelope: func(items interface{}, info httpmock.PageInfo) interface{} {
+				return map[string]interface{}{"data": items, "next": info.NextURL}
+			},
+		})
+
+		req, err := http.NewRequest(http.MethodGet, "http://z.tld/items", nil)
+		td.Require(assert).CmpNoError(err)
+		resp, err := responder(req)
+		td.Require(assert).CmpNoError(err)
+		assertBody(assert, resp,
+			`{"data":["a","b"],"next":"http://z.tld/items?page=2\u0026per_page=2"}`)
+	})
+
+	assert.CmpPanic(func() { httpmock.NewPaginatedResponder(12, httpmock.Pagination{}) },
+		"NewPaginatedResponder bad items type int. Only slices and arrays are allowed")
+}
@@ -0,0 +1,222 @@
+package httpmock
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil" //nolint: staticcheck
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+)
+
+// byteRange is a satisfiable range of a Range header, both ends
+// included.
+type byteRange struct {
+	start, end int64
+}
+
+func (br byteRange) contentRange(size int64) string {
+	return fmt.Sprintf("bytes %d-%d/%d", br.start, br.end, size)
+}
+
+// parseRange parses the Range header value s for a representation of
+// size bytes, as described in RFC 9110 section 14.1.2. ok is false if
+// s is not a valid bytes range set, so the Range header has to be
+// ignored. The returned ranges are the satisfiable ones, so if none,
+// a 416 Range Not Satisfiable response is expected.
+func parseRange(s string, size int64) (ranges []byteRange, ok bool) {
+	i := strings.IndexByte(s, '=')
+	if i < 0 || !strings.EqualFold(strings.TrimSpace(s[:i]), "bytes") {
+		return nil, false
+	}
+	set := s[i+1:]
+	specs := 0
+	for _, spec := range strings.Split(set, ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		specs++
+		dash := strings.IndexByte(spec, '-')
+		if dash < 0 {
+			return nil, false
+		}
+		first, last := spec[:dash], spec[dash+1:]
+
+		if first == "" { // suffix range: -N
+			n, err := strconv.ParseInt(last, 10, 64)
+			if err != nil || n < 0 {
+				return nil, false
+			}
+			if n == 0 || size == 0 {
+				continue
+			}
+			if n > size {
+				n = size
+			}
+			ranges = append(ranges, byteRange{start: size - n, end: size - 1})
+			continue
+		}
+
+		start, err := strconv.ParseInt(first, 10, 64)
+		if err != nil || start < 0 {
+			return nil, false
+		}
+		end := size - 1
+		if last != "" {
+			end, err = strconv.ParseInt(last, 10, 64)
+			if err != nil || end < start {
+				return nil, false
+			}
+			if end >= size {
+				end = size - 1
+			}
+		}
+		if start >= size {
+			continue
+		}
+		ranges = append(ranges, byteRange{start: start, end: end})
+	}
+	return ranges, specs > 0
+}
+
+// ifRangeMatches returns whether the If-Range header value ifRange
+// matches the validators of resp, as described in RFC 9110 section
+// 13.1.5: an entity tag must strongly match the ETag header, a date
+// must be exactly the Last-Modified header one.
+func ifRangeMatches(ifRange string, resp *http.Response) bool {
+	if strings.HasPrefix(ifRange, `"`) || strings.HasPrefix(ifRange, "W/") {
+		etag := resp.Header.Get("ETag")
+		return !strings.HasPrefix(ifRange, "W/") && etag != "" && etagMatch(ifRange, etag, false)
+	}
+	date, err := http.ParseTime(ifRange)
+	if err != nil {
+		return false
+	}
+	lastModified, err := http.ParseTime(resp.Header.Get("Last-Modified"))
+	return err == nil && lastModified.Equal(date)
+}
+
+// Ranges returns a new [Responder] based on r that honors the Range
+// and If-Range headers of GET requests, as described in RFC 9110
+// section 14.
+//
+// Only 200 responses of r are concerned, others are returned as
+// is. An "Accept-Ranges: bytes" header is added to them. Then:
+//   - if the request has no Range header, if it is not a valid bytes
+//     range set, or if the If-Range header does not match the ETag
+//     or Last-Modified response header, the full response is
+//     returned;
+//   - if no range is satisfiable, a 416 Range Not Satisfiable
+//     response is returned, with a Content-Range header containing
+//     the complete length;
+//   - if one range is satisfiable, a 206 Partial Content response is
+//     returned, with the corresponding Content-Range header;
+//   - otherwise, a 206 Partial Content response with a
+//     multipart/byteranges body is returned, each part having the
+//     Content-Type of r response and its own Content-Range header.
+//
+// As the whole body of r response is read, [Responder.Conditional]
+// can be used before Ranges to get ETag and Last-Modified headers
+// for If-Range handling:
+//
+//	httpmock.RegisterResponder("GET", "/big.bin",
+//	  httpmock.NewBytesResponder(200, httpmock.File("big.bin").Bytes()).
+//	    Conditional(httpmock.Conditional{}).
+//	    Ranges())
+//
+// See also [NewRangeResponder].
+func (r Responder) Ranges() Responder {
+	return func(req *http.Request) (*http.Response, error) {
+		resp, err := r(req)
+		if err != nil || resp.StatusCode != http.StatusOK {
+			return resp, err
+		}
+
+		nr := *resp
+		if nr.Header == nil {
+			nr.Header = http.Header{}
+		}
+		nr.Header = nr.Header.Clone()
+		nr.Header.Set("Accept-Ranges", "bytes")
+
+		rangeHeader := req.Header.Get("Range")
+		if req.Method != http.MethodGet || rangeHeader == "" || nr.Body == nil {
+			return &nr, nil
+		}
+		if ifRange := req.Header.Get("If-Range"); ifRange != "" && !ifRangeMatches(ifRange, &nr) {
+			return &nr, nil
+		}
+
+		body, err := ioutil.ReadAll(nr.Body)
+		nr.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		size := int64(len(body))
+		nr.Body = buffer{bytes.NewReader(body)}
+
+		ranges, ok := parseRange(rangeHeader, size)
+		if !ok {
+			return &nr, nil
+		}
+
+		var pr *http.Response
+		switch len(ranges) {
+		case 0:
+			pr = NewStringResponse(http.StatusRequestedRangeNotSatisfiable,
+				http.StatusText(http.StatusRequestedRangeNotSatisfiable))
+			pr.Header.Set("Content-Range", "bytes */"+strconv.FormatInt(size, 10))
+			pr.Request = req
+			return pr, nil
+
+		case 1:
+			br := ranges[0]
+			pr = NewBytesResponse(http.StatusPartialContent, body[br.start:br.end+1])
+			for k, v := range nr.Header {
+				pr.Header[k] = v
+			}
+			pr.Header.Set("Content-Range", br.contentRange(size))
+
+		default:
+			parts := make([]MultipartPart, len(ranges))
+			for i, br := range ranges {
+				parts[i] = MultipartPart{
+					ContentType: nr.Header.Get("Content-Type"),
+					Header: textproto.MIMEHeader{
+						"Content-Range": {br.contentRange(size)},
+					},
+					Content: body[br.start : br.end+1],
+				}
+			}
+			pr = NewMultipartResponse(http.StatusPartialContent, "byteranges", parts...)
+			contentType := pr.Header.Get("Content-Type")
+			for k, v := range nr.Header {
+				pr.Header[k] = v
+			}
+			pr.Header.Set("Content-Type", contentType)
+		}
+
+		pr.ContentLength = int64(pr.Body.(interface{ Len() int }).Len())
+		pr.Header.Set("Content-Length", strconv.FormatInt(pr.ContentLength, 10))
+		pr.Request = req
+		return pr, nil
+	}
+}
+
+// NewRangeResponder creates a [Responder] serving body with a 200
+// status, honoring Range and If-Range request headers (see
+// [Responder.Ranges]). The response contains a strong ETag computed
+// from body (see [Responder.Conditional]), so If-Range with this ETag
+// works out of the box.
+//
+// To pass the content of an existing file as body use [File] as in:
+//
+//	httpmock.NewRangeResponder(httpmock.File("big.bin").Bytes())
+func NewRangeResponder(body []byte) Responder {
+	return NewBytesResponder(http.StatusOK, body).
+		SetContentLength().
+		Conditional(Conditional{}).
+		Ranges()
+}
@@ -0,0 +1,141 @@
+package httpmock_test
+
+import (
+	"io/ioutil" //nolint: staticcheck
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+
+	"github.com/jarcoal/httpmock"
+)
+
+func TestNewRangeResponder(t *testing.T) {
+	assert, require := td.AssertRequire(t)
+
+	const content = "0123456789abcdefghij" // 20 bytes
+	responder := httpmock.NewRangeResponder([]byte(content))
+
+	do := func(method string, headers map[string]string) *http.Response {
+		req, err := http.NewRequest(method, "http://z.tld/file", nil)
+		require.CmpNoError(err)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		resp, err := responder(req)
+		require.CmpNoError(err)
+		return resp
+	}
+
+	// Full content
+	resp := do("GET", nil)
+	assert.Cmp(resp.StatusCode, 200)
+	assert.Cmp(resp.Header.Get("Accept-Ranges"), "bytes")
+	assert.Cmp(resp.Header.Get("Content-Length"), "20")
+	etag := resp.Header.Get("ETag")
+	assert.NotEmpty(etag)
+	assertBody(assert, resp, content)
+
+	// Single ranges
+	for _, tc := range []struct {
+		rng, contentRange, body string
+	}{
+		{"bytes=0-4", "bytes 0-4/20", "01234"},
+		{"bytes=10-", "bytes 10-19/20", "abcdefghij"},
+		{"bytes=-3", "bytes 17-19/20", "hij"},
+		{"bytes=15-100", "bytes 15-19/20", "fghij"},
+		{"bytes=-100", "bytes 0-19/20", content},
+		{"bytes=50-60, 2-3", "bytes 2-3/20", "23"},
+	} {
+		resp = do("GET", map[string]string{"Range": tc.rng})
+		assert.Cmp(resp.StatusCode, http.StatusPartialContent, tc.rng)
+		assert.Cmp(resp.Header.Get("Content-Range"), tc.contentRange, tc.rng)
+		assert.Cmp(resp.ContentLength, int64(len(tc.body)), tc.rng)
+		assert.Cmp(resp.Header.Get("ETag"), etag, tc.rng)
+		assertBody(assert, resp, tc.body)
+	}
+
+	// Multiple ranges
+	resp = do("GET", map[string]string{"Range": "bytes=0-1, -2"})
+	assert.Cmp(resp.StatusCode, http.StatusPartialContent)
+	assert.Cmp(resp.Header.Get("Content-Range"), "")
+	mediaType, params, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	require.CmpNoError(err)
+	assert.Cmp(mediaType, "multipart/byteranges")
+	mr := multipart.NewReader(resp.Body, params["boundary"])
+	for _, expected := range []struct{ contentRange, body string }{
+		{"bytes 0-1/20", "01"},
+		{"bytes 18-19/20", "ij"},
+	} {
+		part, err := mr.NextPart()
+		require.CmpNoError(err)
+		assert.Cmp(part.Header.Get("Content-Range"), expected.contentRange)
+		b, err := ioutil.ReadAll(part)
+		require.CmpNoError(err)
+		assert.Cmp(string(b), expected.body)
+	}
+	_, err = mr.NextPart()
+	assert.Cmp(err, td.NotNil())
+
+	// Not satisfiable
+	for _, rng := range []string{"bytes=20-", "bytes=-0", "bytes=30-40, 50-"} {
+		resp = do("GET", map[string]string{"Range": rng})
+		assert.Cmp(resp.StatusCode, http.StatusRequestedRangeNotSatisfiable, rng)
+		assert.Cmp(resp.Header.Get("Content-Range"), "bytes */20", rng)
+	}
+
+	// Invalid ranges are ignored
+	for _, rng := range []string{"items=0-4", "bytes=4-2", "bytes=x-", "bytes=0", "bytes= , "} {
+		resp = do("GET", map[string]string{"Range": rng})
+		assert.Cmp(resp.StatusCode, 200, rng)
+		assertBody(assert, resp, content)
+	}
+
+	// Range is only for GET
+	assert.Cmp(do("POST", map[string]string{"Range": "bytes=0-4"}).StatusCode, 200)
+
+	// If-Range
+	resp = do("GET", map[string]string{"Range": "bytes=0-4", "If-Range": etag})
+	assert.Cmp(resp.StatusCode, http.StatusPartialContent)
+	resp = do("GET", map[string]string{"Range": "bytes=0-4", "If-Range": `"old"`})
+	assert.Cmp(resp.StatusCode, 200)
+	resp = do("GET", map[string]string{"Range": "bytes=0-4", "If-Range": "W/" + etag})
+	assert.Cmp(resp.StatusCode, 200)
+}
+
+func TestResponderRanges(t *testing.T) {
+	assert, require := td.AssertRequire(t)
+
+	lastModified := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	responder := httpmock.NewStringResponder(200, "Hello world!").
+		HeaderSet(http.Header{"Content-Type": {"text/plain"}}).
+		Conditional(httpmock.Conditional{NoETag: true, LastModified: lastModified}).
+		Ranges()
+
+	req, err := http.NewRequest("GET", "http://z.tld/", nil)
+	require.CmpNoError(err)
+	req.Header.Set("Range", "bytes=6-10")
+
+	// If-Range with a date
+	req.Header.Set("If-Range", lastModified.Format(http.TimeFormat))
+	resp, err := responder(req)
+	require.CmpNoError(err)
+	assert.Cmp(resp.StatusCode, http.StatusPartialContent)
+	assert.Cmp(resp.Header.Get("Content-Type"), "text/plain")
+	assertBody(assert, resp, "world")
+
+	req.Header.Set("If-Range", lastModified.Add(-time.Hour).Format(http.TimeFormat))
+	resp, err = responder(req)
+	require.CmpNoError(err)
+	assert.Cmp(resp.StatusCode, 200)
+	assertBody(assert, resp, "Hello world!")
+
+	// Non-200 responses are untouched
+	resp, err = httpmock.NewStringResponder(404, "Not found").Ranges()(req)
+	require.CmpNoError(err)
+	assert.Cmp(resp.StatusCode, 404)
+	assert.Cmp(resp.Header.Get("Accept-Ranges"), "")
+}
@@ -0,0 +1,183 @@
+package httpmock
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimitAlgorithm is the algorithm used by [Responder.RateLimit].
+type RateLimitAlgorithm int
+
+const (
+	// FixedWindow allows Limit requests per Window. The first window
+	// starts with the first request, the next one starts with the
+	// first request following the end of the previous window.
+	FixedWindow RateLimitAlgorithm = iota
+	// TokenBucket uses a bucket of Limit tokens, refilled
+	// continuously at a rate of Limit tokens per Window. Each request
+	// consumes one token.
+	TokenBucket
+)
+
+// RateLimit configures [Responder.RateLimit].
+type RateLimit struct {
+	Algorithm RateLimitAlgorithm
+	// Limit is the maximum number of requests allowed per Window
+	// (FixedWindow) or the bucket capacity (TokenBucket).
+	Limit int
+	// Window is the duration of a window (FixedWindow) or the duration
+	// needed to completely refill the bucket (TokenBucket).
+	Window time.Duration
+	// KeyHeader, if non-empty, is the name of the request header whose
+	// value is used to compute a distinct limit, for example
+	// "X-Api-Key". If empty, all requests share the same limit.
+	KeyHeader string
+	// Key, if non-nil, is used instead of KeyHeader to compute the
+	// key of each request.
+	Key func(req *http.Request) string
+	// Clock is the time source. If nil, the clock of the
+	// [MockTransport] is used (see [MockTransport.SetClock]).
+	Clock Clock
+}
+
+type rateLimitState struct {
+	// FixedWindow
+	start time.Time
+	count int
+	// TokenBucket
+	tokens float64
+	last   time.Time
+}
+
+type rateLimiter struct {
+	RateLimit
+	mu     sync.Mutex
+	states map[string]*rateLimitState
+}
+
+// take consumes one request for key. It returns whether the request
+// is allowed, the remaining number of requests, the time at which
+// the limit is completely reset and, if not allowed, the duration to
+// wait before retrying.
+func (rl *rateLimiter) take(key string, now time.Time) (ok bool, remaining int, reset time.Time, retry time.Duration) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	st := rl.states[key]
+	if st == nil {
+		st = &rateLimitState{start: now, tokens: float64(rl.Limit), last: now}
+		rl.states[key] = st
+	}
+
+	if rl.Algorithm == TokenBucket {
+		rate := float64(rl.Limit) / float64(rl.Window)
+		st.tokens = math.Min(float64(rl.Limit), st.tokens+float64(now.Sub(st.last))*rate)
+		st.last = now
+		if st.tokens >= 1 {
+			st.tokens--
+			ok = true
+		} else {
+			retry = time.Duration(math.Ceil((1 - st.tokens) / rate))
+		}
+		remaining = int(st.tokens)
+		reset = now.Add(time.Duration(math.Ceil((float64(rl.Limit) - st.tokens) / rate)))
+		return
+	}
+
+	if !now.Before(st.start.Add(rl.Window)) {
+		st.start, st.count = now, 0
+	}
+	reset = st.start.Add(rl.Window)
+	if st.count < rl.Limit {
+		st.count++
+		ok = true
+	} else {
+		retry = reset.Sub(now)
+	}
+	remaining = rl.Limit - st.count
+	return
+}
+
+// RateLimit returns a new [Responder] that calls the original r
+// Responder only if the rate limit described by rl is not
+// exceeded. Otherwise it returns a 429 Too Many Requests response
+// with a Retry-After header set to the number of seconds to wait
+// before retrying.
+//
+// Each response, including 429 ones, contains the headers
+// X-RateLimit-Limit (rl.Limit), X-RateLimit-Remaining (number of
+// requests still allowed) and X-RateLimit-Reset (Unix time in seconds
+// at which the limit is completely reset).
+//
+// As the returned [Responder] is typically registered for one route,
+// the limit applies per route. Use rl.KeyHeader or rl.Key to apply it
+// per request header value, for example per API key.
+//
+// A panic occurs if rl.Limit or rl.Window is not strictly positive.
+//
+//	import (
+//	  "testing"
+//	  "time"
+//	  "github.com/jarcoal/httpmock"
+//	)
+//	...
+//	func TestMyApp(t *testing.T) {
+//	  ...
+//	  // 10 requests per minute and per API key
+//	  httpmock.RegisterResponder("GET", "/foo/bar",
+//	    httpmock.NewStringResponder(200, "{}").RateLimit(httpmock.RateLimit{
+//	      Limit:     10,
+//	      Window:    time.Minute,
+//	      KeyHeader: "X-Api-Key",
+//	    }),
+//	  )
+func (r Responder) RateLimit(rl RateLimit) Responder {
+	if rl.Limit <= 0 || rl.Window <= 0 {
+		panic("RateLimit() needs a strictly positive Limit and Window")
+	}
+	limiter := &rateLimiter{
+		RateLimit: rl,
+		states:    map[string]*rateLimitState{},
+	}
+
+	return func(req *http.Request) (*http.Response, error) {
+		var key string
+		if rl.Key != nil {
+			key = rl.Key(req)
+		} else if rl.KeyHeader != "" {
+			key = req.Header.Get(rl.KeyHeader)
+		}
+
+		clock := rl.Clock
+		if clock == nil {
+			clock = clockOf(req)
+		}
+
+		ok, remaining, reset, retry := limiter.take(key, clock.Now())
+
+		var nr http.Response
+		if ok {
+			resp, err := r(req)
+			if err != nil {
+				return nil, err
+			}
+			nr = *resp
+			if nr.Header == nil {
+				nr.Header = http.Header{}
+			}
+			nr.Header = nr.Header.Clone()
+		} else {
+			nr = *NewStringResponse(http.StatusTooManyRequests, "Too Many Requests")
+			nr.Header.Set("Retry-After", strconv.FormatInt(int64(math.Ceil(retry.Seconds())), 10))
+			nr.Request = req
+		}
+
+		nr.Header.Set("X-RateLimit-Limit", strconv.Itoa(rl.Limit))
+		nr.Header.Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+		nr.Header.Set("X-RateLimit-Reset", strconv.FormatInt(int64(math.Ceil(float64(reset.UnixNano())/1e9)), 10))
+		return &nr, nil
+	}
+}
@@ -0,0 +1,100 @@
+package httpmock_test
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+
+	"github.com/jarcoal/httpmock"
+)
+
+func TestResponderRateLimit(t *testing.T) {
+	assert := td.Assert(t)
+
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	call := func(assert *td.T, r httpmock.Responder, apiKey string) *http.Response {
+		assert.Helper()
+		req, err := http.NewRequest(http.MethodGet, testURL, nil)
+		td.Require(assert).CmpNoError(err)
+		if apiKey != "" {
+			req.Header.Set("X-Api-Key", apiKey)
+		}
+		resp, err := r(req)
+		td.Require(assert).CmpNoError(err)
+		return resp
+	}
+
+	assert.Run("fixed window", func(assert *td.T) {
+		clock := httpmock.NewVirtualClock(start)
+		r := httpmock.NewStringResponder(200, "OK").RateLimit(httpmock.RateLimit{
+			Limit:     2,
+			Window:    time.Minute,
+			KeyHeader: "X-Api-Key",
+			Clock:     clock,
+		})
+		reset := strconv.FormatInt(start.Add(time.Minute).Unix(), 10)
+
+		resp := call(assert, r, "A")
+		assert.Cmp(resp.StatusCode, 200)
+		assert.Cmp(resp.Header, td.SuperMapOf(http.Header{
+			"X-Ratelimit-Limit":     {"2"},
+			"X-Ratelimit-Remaining": {"1"},
+			"X-Ratelimit-Reset":     {reset},
+		}, nil))
+		assertBody(assert, resp, "OK")
+
+		clock.Advance(10 * time.Second)
+		resp = call(assert, r, "A")
+		assert.Cmp(resp.StatusCode, 200)
+		assert.Cmp(resp.Header.Get("X-RateLimit-Remaining"), "0")
+
+		resp = call(assert, r, "A")
+		assert.Cmp(resp.StatusCode, http.StatusTooManyRequests)
+		assert.Cmp(resp.Header.Get("Retry-After"), "50")
+		assert.Cmp(resp.Header.Get("X-RateLimit-Remaining"), "0")
+		assert.Cmp(resp.Header.Get("X-RateLimit-Reset"), reset)
+
+		// Another key has its own limit
+		resp = call(assert, r, "B")
+		assert.Cmp(resp.StatusCode, 200)
+
+		clock.Advance(50 * time.Second)
+		resp = call(assert, r, "A")
+		assert.Cmp(resp.StatusCode, 200)
+		assert.Cmp(resp.Header.Get("X-RateLimit-Remaining"), "1")
+	})
+
+	assert.Run("token bucket", func(assert *td.T) {
+		clock := httpmock.NewVirtualClock(start)
+		r := httpmock.NewStringResponder(200, "OK").RateLimit(httpmock.RateLimit{
+			Algorithm: httpmock.TokenBucket,
+			Limit:     3,
+			Window:    3 * time.Second,
+			Clock:     clock,
+		})
+
+		for i := 2; i >= 0; i-- {
+			resp := call(assert, r, "")
+			assert.Cmp(resp.StatusCode, 200)
+			assert.Cmp(resp.Header.Get("X-RateLimit-Remaining"), strconv.Itoa(i))
+		}
+
+		resp := call(assert, r, "")
+		assert.Cmp(resp.StatusCode, http.StatusTooManyRequests)
+		assert.Cmp(resp.Header.Get("Retry-After"), "1")
+		assert.Cmp(resp.Header.Get("X-RateLimit-Reset"),
+			strconv.FormatInt(start.Add(3*time.Second).Unix(), 10))
+
+		clock.Advance(1500 * time.Millisecond)
+		resp = call(assert, r, "")
+		assert.Cmp(resp.StatusCode, 200)
+		assert.Cmp(resp.Header.Get("X-RateLimit-Remaining"), "0")
+	})
+
+	assert.CmpPanic(func() { httpmock.NewStringResponder(200, "").RateLimit(httpmock.RateLimit{}) },
+		"RateLimit() needs a strictly positive Limit and Window")
+}
@@ -0,0 +1,258 @@
+package httpmock
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// RedirectHop is a request received by a [RedirectChain].
+type RedirectHop struct {
+	Method string
+	URL    string
+	Header http.Header
+	Body   []byte
+	// Status is the status code of the response sent back: a redirect
+	// one, except for the final step.
+	Status int
+}
+
+type redirectStep struct {
+	url      string // absolute
+	status   int    // redirect status, 0 for the final step
+	location string // as sent in the Location header
+}
+
+// RedirectChain is a chain of redirections, from a start URL to a
+// final [Responder], recording the hops followed by the client. It is
+// created by [NewRedirectChain] and registered using
+// [MockTransport.RegisterRedirectChain].
+//
+//	chain := httpmock.NewRedirectChain("http://a.tld/old").
+//	  Redirect(301, "/new").
+//	  Redirect(307, "http://b.tld/final").
+//	  To(httpmock.NewStringResponder(200, "OK"))
+//	httpmock.RegisterRedirectChain(chain)
+//
+//	// ... do requests ...
+//
+//	if err := chain.Check(); err != nil {
+//	  t.Error(err)
+//	}
+type RedirectChain struct {
+	steps []redirectStep
+	final Responder
+
+	mu   sync.Mutex
+	hops []RedirectHop
+}
+
+// NewRedirectChain returns a new [RedirectChain] starting at the
+// absolute URL start. Without further calls to
+// [RedirectChain.Redirect], it does not redirect at all.
+func NewRedirectChain(start string) *RedirectChain {
+	return &RedirectChain{
+		steps: []redirectStep{{url: start}},
+		final: NewStringResponder(http.StatusOK, ""),
+	}
+}
+
+// Redirect adds a redirection to location using status, that must
+// be 301, 302, 303, 307 or 308, otherwise a panic occurs. location
+// can be relative to the current last URL of c, or absolute, possibly
+// to another host. It returns c.
+//
+// Each URL of the chain should only appear once.
+func (c *RedirectChain) Redirect(status int, location string) *RedirectChain {
+	switch status {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+	default:
+		panic(fmt.Sprintf("RedirectChain.Redirect bad status %d", status))
+	}
+
+	last := &c.steps[len(c.steps)-1]
+	base, err := url.Parse(last.url)
+	if err != nil {
+		panic(fmt.Sprintf("RedirectChain.Redirect bad URL %q: %s", last.url, err))
+	}
+	target, err := base.Parse(location)
+	if err != nil {
+		panic(fmt.Sprintf("RedirectChain.Redirect bad location %q: %s", location, err))
+	}
+
+	last.status, last.location = status, location
+	c.steps = append(c.steps, redirectStep{url: target.String()})
+	return c
+}
+
+// To sets the [Responder] called at the end of the chain. If not
+// called, an empty 200 response is returned. It returns c.
+func (c *RedirectChain) To(final Responder) *RedirectChain {
+	c.final = final
+	return c
+}
+
+// responder returns the [Responder] of the step i of c.
+func (c *RedirectChain) responder(i int) Responder {
+	step := c.steps[i]
+	return func(req *http.Request) (*http.Response, error) {
+		// Record the hop before the final responder possibly reads the body
+		hop := RedirectHop{
+			Method: req.Method,
+			URL:    req.URL.String(),
+			Header: req.Header.Clone(),
+		}
+		hop.Body, _ = readBody(req)
+
+		var (
+			resp *http.Response
+			err  error
+		)
+		if step.status == 0 {
+			resp, err = c.final(req)
+		} else {
+			resp = NewStringResponse(step.status, "")
+			resp.Header.Set("Location", step.location)
+			resp.Request = req
+		}
+
+		if resp != nil {
+			hop.Status = resp.StatusCode
+		}
+		c.mu.Lock()
+		c.hops = append(c.hops, hop)
+		c.mu.Unlock()
+
+		return resp, err
+	}
+}
+
+// Hops returns the requests received by c, in order.
+func (c *RedirectChain) Hops() []RedirectHop {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]RedirectHop(nil), c.hops...)
+}
+
+// Reset forgets the hops recorded by c.
+func (c *RedirectChain) Reset() {
+	c.mu.Lock()
+	c.hops = nil
+	c.mu.Unlock()
+}
+
+// sensitiveRedirectHeaders are the headers that must not be
+// forwarded to another host during a redirection.
+var sensitiveRedirectHeaders = []string{"Authorization", "Www-Authenticate", "Cookie", "Cookie2"}
+
+// sameOrSubdomain returns whether host is prev or one of its
+// subdomains, in which case sensitive headers can be forwarded.
+func sameOrSubdomain(prev, host string) bool {
+	prev, host = strings.ToLower(prev), strings.ToLower(host)
+	return host == prev || strings.HasSuffix(host, "."+prev)
+}
+
+// Check checks that the client followed the whole chain c once, as
+// described in RFC 9110 section 15.4, and returns an error detailing
+// each problem if not:
+//   - each hop must request the URL redirected to;
+//   - after a 301 or 302, a GET or HEAD method must be kept; other
+//     methods can be kept with the same body or changed to GET
+//     without body;
+//   - after a 303, the method must be GET (or HEAD if it was HEAD)
+//     without body;
+//   - after a 307 or 308, the method and body must be kept;
+//   - when redirected to another host (that is not a subdomain),
+//     Authorization, Www-Authenticate, Cookie and Cookie2 headers
+//     must not be forwarded with the same value.
+func (c *RedirectChain) Check() error {
+	hops := c.Hops()
+
+	var problems []string
+	if len(hops) != len(c.steps) {
+		problems = append(problems,
+			fmt.Sprintf("%d hops expected, but %d received", len(c.steps), len(hops)))
+	}
+
+	for i := 0; i < len(hops) && i < len(c.steps); i++ {
+		cur := hops[i]
+		if cur.URL != c.steps[i].url {
+			problems = append(problems,
+				fmt.Sprintf("hop #%d: %s %s received, but %s expected", i+1, cur.Method, cur.URL, c.steps[i].url))
+		}
+		if i == 0 {
+			continue
+		}
+
+		prev := hops[i-1]
+		status := c.steps[i-1].status
+		sameBody := bytes.Equal(cur.Body, prev.Body)
+		report := func(format string, args ...any) {
+			problems = append(problems,
+				fmt.Sprintf("hop #%d after %d: ", i+1, status)+fmt.Sprintf(format, args...))
+		}
+
+		switch status {
+		case http.StatusMovedPermanently, http.StatusFound:
+			switch {
+			case cur.Method == prev.Method:
+				if !sameBody {
+					report("%s body changed", cur.Method)
+				}
+			case prev.Method == http.MethodGet || prev.Method == http.MethodHead:
+				report("method %s should have been kept, got %s", prev.Method, cur.Method)
+			case cur.Method != http.MethodGet:
+				report("method %s should have been kept or changed to GET, got %s", prev.Method, cur.Method)
+			case len(cur.Body) > 0:
+				report("body should have been dropped when changing %s to GET", prev.Method)
+			}
+
+		case http.StatusSeeOther:
+			expected := http.MethodGet
+			if prev.Method == http.MethodHead {
+				expected = http.MethodHead
+			}
+			if cur.Method != expected {
+				report("method should have been changed to %s, got %s", expected, cur.Method)
+			}
+			if len(cur.Body) > 0 {
+				report("body should have been dropped")
+			}
+
+		case http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+			if cur.Method != prev.Method {
+				report("method %s should have been kept, got %s", prev.Method, cur.Method)
+			}
+			if !sameBody {
+				report("body should have been kept")
+			}
+		}
+
+		prevURL, _ := url.Parse(prev.URL)
+		curURL, _ := url.Parse(cur.URL)
+		if prevURL != nil && curURL != nil && !sameOrSubdomain(prevURL.Hostname(), curURL.Hostname()) {
+			for _, key := range sensitiveRedirectHeaders {
+				if value := prev.Header.Get(key); value != "" && cur.Header.Get(key) == value {
+					report("%s header forwarded from %s to %s", key, prevURL.Host, curURL.Host)
+				}
+			}
+		}
+	}
+
+	if problems == nil {
+		return nil
+	}
+	return errors.New("redirect chain: " + strings.Join(problems, "\n\t"))
+}
+
+// redirectMethods are the methods for which
+// [MockTransport.RegisterRedirectChain] registers responders.
+var redirectMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+	http.MethodPatch, http.MethodDelete, http.MethodOptions,
+}
@@ -0,0 +1,121 @@
+package httpmock_test
+
+import (
+	"io/ioutil" //nolint: staticcheck
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/td"
+
+	"github.com/jarcoal/httpmock"
+)
+
+func TestRedirectChain(t *testing.T) {
+	assert, require := td.AssertRequire(t)
+
+	mt := httpmock.NewMockTransport()
+	client := &http.Client{Transport: mt}
+
+	chain := httpmock.NewRedirectChain("http://a.tld/start").
+		Redirect(307, "/temp").
+		Redirect(308, "http://sub.a.tld/perm").
+		Redirect(302, "http://b.tld/found").
+		Redirect(303, "/other").
+		To(httpmock.NewStringResponder(200, "done"))
+	mt.RegisterRedirectChain(chain)
+
+	req, err := http.NewRequest("POST", "http://a.tld/start", strings.NewReader("payload"))
+	require.CmpNoError(err)
+	req.Header.Set("Authorization", "Bearer t0k3n")
+	resp, err := client.Do(req)
+	require.CmpNoError(err)
+	assertBody(assert, resp, "done")
+
+	hops := chain.Hops()
+	assert.Cmp(hops[2].Header.Get("Authorization"), "Bearer t0k3n")
+	assert.Cmp(hops[3].Header.Get("Authorization"), "")
+	for i := range hops {
+		hops[i].Header = nil
+	}
+	assert.Cmp(hops, []httpmock.RedirectHop{
+		{Method: "POST", URL: "http://a.tld/start", Body: []byte("payload"), Status: 307},
+		{Method: "POST", URL: "http://a.tld/temp", Body: []byte("payload"), Status: 308},
+		{Method: "POST", URL: "http://sub.a.tld/perm", Body: []byte("payload"), Status: 302},
+		{Method: "GET", URL: "http://b.tld/found", Status: 303},
+		{Method: "GET", URL: "http://b.tld/other", Status: 200},
+	})
+
+	assert.CmpNoError(chain.Check())
+
+	// Same chain, followed by a naughty client
+	chain.Reset()
+	do := func(method, url, body string, auth bool) {
+		var req *http.Request
+		if body == "" {
+			req, err = http.NewRequest(method, url, nil)
+		} else {
+			req, err = http.NewRequest(method, url, strings.NewReader(body))
+		}
+		require.CmpNoError(err)
+		if auth {
+			req.Header.Set("Authorization", "Bearer t0k3n")
+		}
+		_, err = mt.RoundTrip(req)
+		require.CmpNoError(err)
+	}
+	do("POST", "http://a.tld/start", "payload", true)
+	do("GET", "http://a.tld/temp", "", true)
+	do("POST", "http://sub.a.tld/perm", "changed", true)
+	do("PUT", "http://b.tld/found", "", true)
+	do("POST", "http://b.tld/other", "x", false)
+
+	err = chain.Check()
+	assert.String(err, `redirect chain: hop #2 after 307: method POST should have been kept, got GET
+	hop #2 after 307: body should have been kept
+	hop #3 after 308: method GET should have been kept, got POST
+	hop #3 after 308: body should have been kept
+	hop #4 after 302: method POST should have been kept or changed to GET, got PUT
+	hop #4 after 302: Authorization header forwarded from sub.a.tld to b.tld
+	hop #5 after 303: method should have been changed to GET, got POST
+	hop #5 after 303: body should have been dropped`)
+
+	// Incomplete chain
+	chain.Reset()
+	do("GET", "http://a.tld/start", "", false)
+	assert.String(chain.Check(), "redirect chain: 5 hops expected, but 1 received")
+
+	// Only redirects
+	assert.String(httpmock.NewRedirectChain("http://a.tld/x").Redirect(301, "/y").Check(),
+		"redirect chain: 2 hops expected, but 0 received")
+
+	assert.CmpPanic(func() { httpmock.NewRedirectChain("http://a.tld/").Redirect(200, "/") },
+		"RedirectChain.Redirect bad status 200")
+}
+
+func TestRedirectChainFinalReadsBody(t *testing.T) {
+	assert, require := td.AssertRequire(t)
+
+	mt := httpmock.NewMockTransport()
+	client := &http.Client{Transport: mt}
+
+	for _, status := range []int{307, 308} {
+		chain := httpmock.NewRedirectChain("http://a.tld/start").
+			Redirect(status, "/final").
+			To(func(req *http.Request) (*http.Response, error) {
+				b, err := ioutil.ReadAll(req.Body)
+				return httpmock.NewBytesResponse(200, b), err
+			})
+		mt.RegisterRedirectChain(chain)
+
+		resp, err := client.Post("http://a.tld/start", "text/plain", strings.NewReader("payload"))
+		require.CmpNoError(err)
+		assertBody(assert, resp, "payload")
+
+		hops := chain.Hops()
+		if assert.Len(hops, 2) {
+			assert.Cmp(hops[1].Body, []byte("payload"), "status %d", status)
+		}
+		assert.CmpNoError(chain.Check(), "status %d", status)
+	}
+}
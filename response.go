@@ -26,6 +26,8 @@ var fromThenKey = fromThenKeyType{}
 type suggestedInfo struct {
 	kind      string
 	suggested string
+	closest   string
+	diff      string
 }
 
 // suggestedMethodKeyType is used by NewNotFoundResponder().
@@ -124,7 +126,12 @@ func (r Responder) Trace(fn func(...any)) Responder {
 }
 
 // Delay returns a new [Responder] that calls the original r Responder
-// after a delay of d.
+// after a delay of d. If the request is canceled during the delay, r
+// is not called and the context error is returned.
+//
+// The delay is measured using the [Clock] of the [MockTransport]
+// (see [MockTransport.SetClock]), so using a [*VirtualClock] avoids
+// really waiting.
 //
 //	import (
 //	  "testing"
@@ -139,7 +146,9 @@ func (r Responder) Trace(fn func(...any)) Responder {
 //	  )
 func (r Responder) Delay(d time.Duration) Responder {
 	return func(req *http.Request) (*http.Response, error) {
-		time.Sleep(d)
+		if err := sleep(req, clockOf(req), d); err != nil {
+			return nil, err
+		}
 		return r(req)
 	}
 }
@@ -459,7 +468,7 @@ func ResponderFromMultipleResponses(responses []*http.Response, fn ...func(...an
 
 // NewErrorResponder creates a [Responder] that returns an empty request and the
 // given error. This can be used to e.g. imitate more deep http errors for the
-// client.
+// client. See [NewNetErrorResponder] for ready-made network errors.
 func NewErrorResponder(err error) Responder {
 	return func(req *http.Request) (*http.Response, error) {
 		return nil, err
@@ -505,7 +514,8 @@ func NewNotFoundResponder(fn func(...any)) Responder {
 		suggested, _ := req.Context().Value(suggestedKey).(*suggestedInfo)
 		if suggested != nil {
 			if suggested.kind == "matcher" {
-				extra = fmt.Sprintf(` despite %s`, suggested.suggested)
+				extra = ` despite ` + suggested.suggested +
+					internal.ClosestMatcher(suggested.closest, suggested.diff)
 			} else {
 				extra = fmt.Sprintf(`, but one matches %s %q`, suggested.kind, suggested.suggested)
 			}
@@ -0,0 +1,146 @@
+package httpmock
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ServerSentEvent is an event sent by a [Responder] returned by
+// [NewSSEResponder] or [NewSSEChanResponder]. Empty fields are not
+// sent.
+type ServerSentEvent struct {
+	ID    string
+	Event string
+	// Data is the payload of the event. If it contains several lines,
+	// each line is sent in its own "data:" field.
+	Data string
+	// Retry is the reconnection time sent to the client.
+	Retry time.Duration
+	// Comment is sent as a comment line, ignored by clients. An event
+	// containing only a comment is typically used as a keep-alive.
+	Comment string
+}
+
+// Bytes returns e encoded as a text/event-stream frame, including
+// the final blank line.
+func (e ServerSentEvent) Bytes() []byte {
+	var b bytes.Buffer
+	if e.Comment != "" {
+		for _, line := range strings.Split(e.Comment, "\n") {
+			b.WriteString(": " + line + "\n")
+		}
+	}
+	if e.ID != "" {
+		b.WriteString("id: " + e.ID + "\n")
+	}
+	if e.Event != "" {
+		b.WriteString("event: " + e.Event + "\n")
+	}
+	if e.Retry > 0 {
+		b.WriteString("retry: " + strconv.FormatInt(e.Retry.Milliseconds(), 10) + "\n")
+	}
+	if e.Data != "" {
+		for _, line := range strings.Split(e.Data, "\n") {
+			b.WriteString("data: " + line + "\n")
+		}
+	}
+	b.WriteByte('\n')
+	return b.Bytes()
+}
+
+func newSSEResponse(req *http.Request, next func(context.Context) ([]byte, error)) *http.Response {
+	resp := newStreamResponse(req, http.StatusOK, next)
+	resp.Header.Set("Content-Type", "text/event-stream")
+	resp.Header.Set("Cache-Control", "no-cache")
+	return resp
+}
+
+// NewSSEResponder creates a [Responder] streaming events as
+// Server-Sent Events (text/event-stream). If interval is > 0, it
+// waits interval before sending each event except the first one.
+// The stream ends after the last event or as soon as the request is
+// canceled, the body Read call then returning the context error.
+//
+// If the request contains a Last-Event-ID header, the stream resumes
+// just after the event with this ID, allowing to test client
+// reconnections. If no event matches, all events are sent.
+//
+// The interval is measured using the [Clock] of the [MockTransport]
+// (see [MockTransport.SetClock]).
+//
+// To test partial frames handling, use [Responder.Throttle] with a
+// small ChunkSize as in:
+//
+//	httpmock.RegisterResponder("GET", "/events",
+//	  httpmock.NewSSEResponder([]httpmock.ServerSentEvent{
+//	    {ID: "1", Event: "update", Data: `{"n":1}`},
+//	    {ID: "2", Event: "update", Data: `{"n":2}`},
+//	  }, 100*time.Millisecond).Throttle(httpmock.Throttling{ChunkSize: 3}),
+//	)
+func NewSSEResponder(events []ServerSentEvent, interval time.Duration) Responder {
+	return func(req *http.Request) (*http.Response, error) {
+		evs := events
+		if last := req.Header.Get("Last-Event-ID"); last != "" {
+			for i, e := range evs {
+				if e.ID == last {
+					evs = evs[i+1:]
+					break
+				}
+			}
+		}
+
+		clock := clockOf(req)
+		i := 0
+		return newSSEResponse(req, func(ctx context.Context) ([]byte, error) {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			if i >= len(evs) {
+				return nil, io.EOF
+			}
+			if i > 0 && interval > 0 {
+				if err := sleepContext(ctx, clock, interval); err != nil {
+					return nil, err
+				}
+			}
+			i++
+			return evs[i-1].Bytes(), nil
+		}), nil
+	}
+}
+
+// NewSSEChanResponder creates a [Responder] streaming events received
+// from ch as Server-Sent Events (text/event-stream). Each event is
+// sent as soon as it is received, so the test controls the pace. The
+// stream ends when ch is closed or as soon as the request is
+// canceled, the body Read call then returning the context error.
+//
+// Note that ch is shared by all the responses returned by this
+// [Responder].
+//
+//	events := make(chan httpmock.ServerSentEvent)
+//	httpmock.RegisterResponder("GET", "/events",
+//	  httpmock.NewSSEChanResponder(events))
+//	...
+//	events <- httpmock.ServerSentEvent{Event: "ping"}
+//	close(events)
+func NewSSEChanResponder(ch <-chan ServerSentEvent) Responder {
+	return func(req *http.Request) (*http.Response, error) {
+		return newSSEResponse(req, func(ctx context.Context) ([]byte, error) {
+			select {
+			case e, ok := <-ch:
+				if !ok {
+					return nil, io.EOF
+				}
+				return e.Bytes(), nil
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}), nil
+	}
+}
@@ -0,0 +1,119 @@
+package httpmock_test
+
+import (
+	"context"
+	"io/ioutil" //nolint: staticcheck
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+
+	"github.com/jarcoal/httpmock"
+)
+
+func TestServerSentEvent(t *testing.T) {
+	td.Cmp(t, string(httpmock.ServerSentEvent{}.Bytes()), "\n")
+	td.Cmp(t,
+		string(httpmock.ServerSentEvent{
+			ID:      "42",
+			Event:   "update",
+			Data:    "line1\nline2",
+			Retry:   3 * time.Second,
+			Comment: "hey",
+		}.Bytes()),
+		": hey\nid: 42\nevent: update\nretry: 3000\ndata: line1\ndata: line2\n\n")
+}
+
+func TestNewSSEResponder(t *testing.T) {
+	assert, require := td.AssertRequire(t)
+
+	events := []httpmock.ServerSentEvent{
+		{ID: "1", Data: "one"},
+		{ID: "2", Data: "two"},
+		{ID: "3", Data: "three"},
+	}
+
+	clock := httpmock.NewVirtualClock(time.Now())
+	mt := httpmock.NewMockTransport()
+	mt.SetClock(clock)
+	mt.RegisterResponder("GET", "/events", httpmock.NewSSEResponder(events, time.Second))
+	client := &http.Client{Transport: mt}
+
+	go func() {
+		for i := 0; i < 2; i++ {
+			clock.BlockUntil(1)
+			clock.Advance(time.Second)
+		}
+	}()
+	resp, err := client.Get("http://z.tld/events")
+	require.CmpNoError(err)
+	assert.Cmp(resp.Header.Get("Content-Type"), "text/event-stream")
+	assert.Cmp(resp.ContentLength, int64(-1))
+	assertBody(assert, resp, "id: 1\ndata: one\n\nid: 2\ndata: two\n\nid: 3\ndata: three\n\n")
+
+	// Reconnection
+	req, err := http.NewRequest("GET", "http://z.tld/events", nil)
+	require.CmpNoError(err)
+	req.Header.Set("Last-Event-ID", "2")
+	resp, err = client.Do(req)
+	require.CmpNoError(err)
+	assertBody(assert, resp, "id: 3\ndata: three\n\n")
+
+	// Cancellation
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err = http.NewRequestWithContext(ctx, "GET", "http://z.tld/events", nil)
+	require.CmpNoError(err)
+	resp, err = client.Do(req)
+	require.CmpNoError(err)
+	buf := make([]byte, 100)
+	n, err := resp.Body.Read(buf)
+	require.CmpNoError(err)
+	assert.Cmp(string(buf[:n]), "id: 1\ndata: one\n\n")
+	cancel()
+	_, err = ioutil.ReadAll(resp.Body)
+	assert.Cmp(err, context.Canceled)
+
+	// Cancellation without interval
+	mt.RegisterResponder("GET", "/now", httpmock.NewSSEResponder(events, 0))
+	ctx, cancel = context.WithCancel(context.Background())
+	req, err = http.NewRequestWithContext(ctx, "GET", "http://z.tld/now", nil)
+	require.CmpNoError(err)
+	resp, err = client.Do(req)
+	require.CmpNoError(err)
+	n, err = resp.Body.Read(buf)
+	require.CmpNoError(err)
+	assert.Cmp(string(buf[:n]), "id: 1\ndata: one\n\n")
+	cancel()
+	_, err = ioutil.ReadAll(resp.Body)
+	assert.Cmp(err, context.Canceled)
+}
+
+func TestNewSSEChanResponder(t *testing.T) {
+	assert, require := td.AssertRequire(t)
+
+	ch := make(chan httpmock.ServerSentEvent)
+	responder := httpmock.NewSSEChanResponder(ch)
+
+	req, err := http.NewRequest("GET", "http://z.tld/events", nil)
+	require.CmpNoError(err)
+	resp, err := responder(req)
+	require.CmpNoError(err)
+
+	go func() {
+		ch <- httpmock.ServerSentEvent{Event: "ping"}
+		ch <- httpmock.ServerSentEvent{Event: "pong", Data: "x"}
+		close(ch)
+	}()
+	assertBody(assert, resp, "event: ping\n\nevent: pong\ndata: x\n\n")
+
+	// Cancellation
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err = http.NewRequestWithContext(ctx, "GET", "http://z.tld/events", nil)
+	require.CmpNoError(err)
+	resp, err = httpmock.NewSSEChanResponder(make(chan httpmock.ServerSentEvent))(req)
+	require.CmpNoError(err)
+	cancel()
+	_, err = ioutil.ReadAll(resp.Body)
+	assert.Cmp(err, context.Canceled)
+}
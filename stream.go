@@ -0,0 +1,161 @@
+package httpmock
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// StreamChunk is a piece of a response body streamed by a
+// [Responder] returned by [NewChanStreamResponder].
+type StreamChunk struct {
+	// Data is delivered to the client, before Err if both are set.
+	Data []byte
+	// Err, if non-nil, is returned by the body Read call once Data
+	// has been delivered. Use [io.EOF] to end the body early.
+	Err error
+}
+
+// NewNDJSONChunk returns a [StreamChunk] containing v encoded as
+// JSON, followed by a new line, as expected in a NDJSON (or JSON
+// lines) stream. If v cannot be encoded, the returned [StreamChunk]
+// contains the encoding error.
+func NewNDJSONChunk(v any) StreamChunk {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return StreamChunk{Err: err}
+	}
+	return StreamChunk{Data: append(b, '\n')}
+}
+
+var errStreamBodyClosed = errors.New("http: read on closed response body")
+
+// streamBody is an io.ReadCloser calling next each time a new piece
+// of data is needed. The context passed to next is canceled when the
+// body is closed, so a blocked Read returns.
+type streamBody struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	next   func(ctx context.Context) ([]byte, error)
+	buf    []byte
+	err    error
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+func (b *streamBody) isClosed() bool {
+	select {
+	case <-b.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+func (b *streamBody) Read(p []byte) (int, error) {
+	if b.isClosed() {
+		return 0, errStreamBodyClosed
+	}
+	for len(b.buf) == 0 {
+		if b.err != nil {
+			return 0, b.err
+		}
+		b.buf, b.err = b.next(b.ctx)
+		if b.isClosed() {
+			return 0, errStreamBodyClosed
+		}
+	}
+	n := copy(p, b.buf)
+	b.buf = b.buf[n:]
+	return n, nil
+}
+
+func (b *streamBody) Close() error {
+	b.closeOnce.Do(func() {
+		close(b.closed)
+		b.cancel()
+	})
+	return nil
+}
+
+func newStreamResponse(req *http.Request, status int, next func(context.Context) ([]byte, error)) *http.Response {
+	ctx, cancel := context.WithCancel(req.Context())
+	return &http.Response{
+		Status:     strconv.Itoa(status),
+		StatusCode: status,
+		Header:     http.Header{},
+		Body: &streamBody{
+			ctx:    ctx,
+			cancel: cancel,
+			next:   next,
+			closed: make(chan struct{}),
+		},
+		ContentLength: -1,
+		Request:       req,
+	}
+}
+
+// NewStreamResponder creates a [Responder] whose response body is
+// produced while the client reads it: each time the client needs
+// more data, next is called with a context derived from the request
+// one, also canceled when the body is closed. The returned
+// data is delivered, then the returned error, if any, is returned by
+// the body Read call. next returns [io.EOF] to end the body.
+//
+// As each call of the [Responder] returns a new body, next is
+// typically built by a closure to keep its state, or uses the request
+// context to wait for something to happen, as in long-polling:
+//
+//	httpmock.RegisterResponder("GET", "/poll",
+//	  httpmock.NewStreamResponder(200, func(ctx context.Context) ([]byte, error) {
+//	    select {
+//	    case msg := <-messages:
+//	      return msg, io.EOF
+//	    case <-ctx.Done():
+//	      return nil, ctx.Err()
+//	    }
+//	  }))
+//
+// See also [NewChanStreamResponder].
+func NewStreamResponder(status int, next func(ctx context.Context) ([]byte, error)) Responder {
+	return func(req *http.Request) (*http.Response, error) {
+		return newStreamResponse(req, status, next), nil
+	}
+}
+
+// NewChanStreamResponder creates a [Responder] whose response body is
+// fed by chunks received from ch while the client reads it. This way
+// the test pushes data, errors or EOF at exact points. Closing ch ends
+// the body, as well as a [StreamChunk] whose Err field is [io.EOF]. If
+// the request is canceled while waiting for a chunk, the body Read
+// call returns the context error.
+//
+// Note that ch is shared by all the responses returned by this
+// [Responder].
+//
+//	chunks := make(chan httpmock.StreamChunk)
+//	httpmock.RegisterResponder("GET", "/items",
+//	  httpmock.NewChanStreamResponder(200, chunks).
+//	    HeaderSet(http.Header{"Content-Type": {"application/x-ndjson"}}))
+//	...
+//	chunks <- httpmock.NewNDJSONChunk(item1)
+//	chunks <- httpmock.NewNDJSONChunk(item2)
+//	chunks <- httpmock.StreamChunk{Err: io.ErrUnexpectedEOF}
+func NewChanStreamResponder(status int, ch <-chan StreamChunk) Responder {
+	return NewStreamResponder(status, func(ctx context.Context) ([]byte, error) {
+		select {
+		case chunk, ok := <-ch:
+			if !ok {
+				return nil, io.EOF
+			}
+			return chunk.Data, chunk.Err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	})
+}
@@ -0,0 +1,130 @@
+package httpmock_test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil" //nolint: staticcheck
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+
+	"github.com/jarcoal/httpmock"
+)
+
+func TestNewNDJSONChunk(t *testing.T) {
+	td.Cmp(t, httpmock.NewNDJSONChunk(map[string]int{"a": 1}),
+		httpmock.StreamChunk{Data: []byte("{\"a\":1}\n")})
+
+	chunk := httpmock.NewNDJSONChunk(func() {})
+	td.CmpNil(t, chunk.Data)
+	td.CmpError(t, chunk.Err)
+}
+
+func TestNewStreamResponder(t *testing.T) {
+	assert, require := td.AssertRequire(t)
+
+	n := 0
+	responder := httpmock.NewStreamResponder(200, func(ctx context.Context) ([]byte, error) {
+		n++
+		switch n {
+		case 1:
+			return []byte("["), nil
+		case 2:
+			return []byte("1,2"), nil
+		default:
+			return []byte("]"), io.EOF
+		}
+	})
+
+	req, err := http.NewRequest("GET", testURL, nil)
+	require.CmpNoError(err)
+	resp, err := responder(req)
+	require.CmpNoError(err)
+	assert.Cmp(resp.StatusCode, 200)
+	assert.Cmp(resp.ContentLength, int64(-1))
+	assert.Cmp(n, 0) // nothing produced until the client reads
+	assertBody(assert, resp, "[1,2]")
+
+	// Read after close
+	_, err = resp.Body.Read(make([]byte, 1))
+	assert.String(err, "http: read on closed response body")
+}
+
+func TestNewChanStreamResponder(t *testing.T) {
+	assert, require := td.AssertRequire(t)
+
+	ch := make(chan httpmock.StreamChunk)
+	mt := httpmock.NewMockTransport()
+	mt.RegisterResponder("GET", "/items", httpmock.NewChanStreamResponder(200, ch))
+	client := &http.Client{Transport: mt}
+
+	resp, err := client.Get("http://z.tld/items")
+	require.CmpNoError(err)
+	lines := bufio.NewScanner(resp.Body)
+
+	go func() { ch <- httpmock.NewNDJSONChunk(1) }()
+	require.True(lines.Scan())
+	assert.Cmp(lines.Text(), "1")
+
+	go func() { ch <- httpmock.NewNDJSONChunk("two") }()
+	require.True(lines.Scan())
+	assert.Cmp(lines.Text(), `"two"`)
+
+	myErr := errors.New("boom")
+	go func() { ch <- httpmock.StreamChunk{Data: []byte("3"), Err: myErr} }()
+	body, err := ioutil.ReadAll(resp.Body)
+	assert.Cmp(err, myErr)
+	assert.Cmp(string(body), "3")
+	resp.Body.Close()
+
+	// Closed channel → EOF
+	resp, err = client.Get("http://z.tld/items")
+	require.CmpNoError(err)
+	go func() {
+		ch <- httpmock.StreamChunk{Data: []byte("end")}
+		close(ch)
+	}()
+	assertBody(assert, resp, "end")
+
+	// Cancellation
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequestWithContext(ctx, "GET", "http://z.tld/items", nil)
+	require.CmpNoError(err)
+	resp, err = httpmock.NewChanStreamResponder(200, make(chan httpmock.StreamChunk))(req)
+	require.CmpNoError(err)
+	cancel()
+	_, err = ioutil.ReadAll(resp.Body)
+	assert.Cmp(err, context.Canceled)
+}
+
+func TestStreamBodyCloseUnblocksRead(t *testing.T) {
+	assert, require := td.AssertRequire(t)
+
+	chunks := make(chan httpmock.StreamChunk)
+	responder := httpmock.NewChanStreamResponder(200, chunks)
+
+	req, err := http.NewRequest("GET", "http://z.tld/items", nil)
+	require.CmpNoError(err)
+	resp, err := responder(req)
+	require.CmpNoError(err)
+
+	errc := make(chan error, 1)
+	go func() {
+		_, err := resp.Body.Read(make([]byte, 10))
+		errc <- err
+	}()
+
+	time.Sleep(10 * time.Millisecond) // let Read block waiting for a chunk
+	require.CmpNoError(resp.Body.Close())
+
+	select {
+	case err := <-errc:
+		assert.String(err, "http: read on closed response body")
+	case <-time.After(5 * time.Second):
+		t.Fatal("Read still blocked after Close")
+	}
+}
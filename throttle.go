@@ -0,0 +1,159 @@
+package httpmock
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Throttling describes how the body of a response is delivered by
+// a [Responder] returned by [Responder.Throttle].
+type Throttling struct {
+	// TimeToFirstByte is the delay before the first body byte is
+	// delivered.
+	TimeToFirstByte time.Duration
+	// BytesPerSecond is the bandwidth. 0 means unlimited.
+	BytesPerSecond int
+	// ChunkSize is the maximum number of bytes returned by each body
+	// Read call. 0 means unlimited.
+	ChunkSize int
+	// StallEvery and StallDuration, if both > 0, make the body stall
+	// during StallDuration each time StallEvery bytes have been
+	// delivered.
+	StallEvery    int
+	StallDuration time.Duration
+}
+
+// NetworkProfiles contains named [Throttling] profiles usable with
+// [Responder.ThrottleProfile]. It can be completed with custom
+// profiles. Available profiles are:
+//   - "slow-3g": 2s to first byte then 50 KB/s;
+//   - "fast-3g": 560ms to first byte then 180 KB/s;
+//   - "lossy": 100ms to first byte then 250 KB/s, stalling 500ms
+//     every 16 KiB.
+//
+// All of them deliver 1460 bytes (a typical TCP segment) at most per
+// Read call.
+var NetworkProfiles = map[string]Throttling{
+	"slow-3g": {
+		TimeToFirstByte: 2 * time.Second,
+		BytesPerSecond:  50000,
+		ChunkSize:       1460,
+	},
+	"fast-3g": {
+		TimeToFirstByte: 560 * time.Millisecond,
+		BytesPerSecond:  180000,
+		ChunkSize:       1460,
+	},
+	"lossy": {
+		TimeToFirstByte: 100 * time.Millisecond,
+		BytesPerSecond:  250000,
+		ChunkSize:       1460,
+		StallEvery:      16 * 1024,
+		StallDuration:   500 * time.Millisecond,
+	},
+}
+
+type throttledBody struct {
+	Throttling
+	body       io.ReadCloser
+	req        *http.Request
+	clock      Clock
+	started    bool
+	sinceStall int
+}
+
+func (b *throttledBody) Read(p []byte) (int, error) {
+	if !b.started {
+		b.started = true
+		if err := sleep(b.req, b.clock, b.TimeToFirstByte); err != nil {
+			return 0, err
+		}
+	}
+
+	if b.ChunkSize > 0 && len(p) > b.ChunkSize {
+		p = p[:b.ChunkSize]
+	}
+	if b.StallEvery > 0 && b.StallDuration > 0 && len(p) > b.StallEvery-b.sinceStall {
+		p = p[:b.StallEvery-b.sinceStall]
+	}
+
+	n, err := b.body.Read(p)
+
+	if n > 0 && b.BytesPerSecond > 0 {
+		d := time.Duration(n) * time.Second / time.Duration(b.BytesPerSecond)
+		if serr := sleep(b.req, b.clock, d); serr != nil {
+			return n, serr
+		}
+	}
+
+	if b.StallEvery > 0 && b.StallDuration > 0 {
+		b.sinceStall += n
+		if b.sinceStall >= b.StallEvery && err == nil {
+			b.sinceStall = 0
+			if serr := sleep(b.req, b.clock, b.StallDuration); serr != nil {
+				return n, serr
+			}
+		}
+	}
+	return n, err
+}
+
+func (b *throttledBody) Close() error {
+	return b.body.Close()
+}
+
+// Throttle returns a new [Responder] based on r whose response body
+// is delivered according to t: after a time to first byte, at a
+// given bandwidth, by chunks and with optional stalls. If the request
+// is canceled during a delay, the body Read call returns the context
+// error.
+//
+// Contrary to [Responder.Delay] that postpones the whole response,
+// the response itself is returned immediately, only the body
+// delivery is slowed down.
+//
+// Delays are measured using the [Clock] of the [MockTransport] (see
+// [MockTransport.SetClock]).
+//
+//	httpmock.RegisterResponder("GET", "/big/file",
+//	  httpmock.NewBytesResponder(200, content).Throttle(httpmock.Throttling{
+//	    TimeToFirstByte: 200 * time.Millisecond,
+//	    BytesPerSecond:  64 * 1024,
+//	    ChunkSize:       1024,
+//	  }),
+//	)
+//
+// See also [Responder.ThrottleProfile].
+func (r Responder) Throttle(t Throttling) Responder {
+	return func(req *http.Request) (*http.Response, error) {
+		resp, err := r(req)
+		if err != nil || resp == nil || resp.Body == nil || resp.Body == http.NoBody {
+			return resp, err
+		}
+		nr := *resp
+		nr.Body = &throttledBody{
+			Throttling: t,
+			body:       resp.Body,
+			req:        req,
+			clock:      clockOf(req),
+		}
+		return &nr, nil
+	}
+}
+
+// ThrottleProfile is like [Responder.Throttle] but uses the
+// [Throttling] profile named name in [NetworkProfiles]. It panics if
+// name is not found.
+//
+//	httpmock.RegisterResponder("GET", "/big/file",
+//	  httpmock.NewBytesResponder(200, content).ThrottleProfile("slow-3g"),
+//	)
+func (r Responder) ThrottleProfile(name string) Responder {
+	t, ok := NetworkProfiles[name]
+	if !ok {
+		panic(fmt.Sprintf("ThrottleProfile: unknown network profile %q", name))
+	}
+	return r.Throttle(t)
+}
@@ -0,0 +1,128 @@
+package httpmock_test
+
+import (
+	"context"
+	"io"
+	"io/ioutil" //nolint: staticcheck
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+
+	"github.com/jarcoal/httpmock"
+)
+
+func TestResponderThrottle(t *testing.T) {
+	assert := td.Assert(t)
+
+	body := strings.Repeat("0123456789", 10)
+
+	call := func(assert *td.T, r httpmock.Responder, ctx context.Context) *http.Response {
+		assert.Helper()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, testURL, nil)
+		td.Require(assert).CmpNoError(err)
+		resp, err := r(req)
+		td.Require(assert).CmpNoError(err)
+		return resp
+	}
+
+	assert.Run("chunks & stalls", func(assert *td.T) {
+		clock := httpmock.NewVirtualClock(time.Now())
+		mt := httpmock.NewMockTransport()
+		mt.SetClock(clock)
+		mt.RegisterResponder("GET", testURL,
+			httpmock.NewStringResponder(200, body).Throttle(httpmock.Throttling{
+				TimeToFirstByte: 20 * time.Millisecond,
+				ChunkSize:       30,
+				StallEvery:      40,
+				StallDuration:   time.Millisecond,
+			}))
+
+		before := clock.Now()
+		resp, err := (&http.Client{Transport: mt}).Get(testURL)
+		td.Require(assert).CmpNoError(err)
+		assert.Cmp(clock.PendingTimers(), 0, "no wait before reading the body")
+
+		go func() {
+			for _, d := range []time.Duration{20 * time.Millisecond, time.Millisecond, time.Millisecond} {
+				clock.BlockUntil(1)
+				clock.Advance(d)
+			}
+		}()
+
+		var sizes []int
+		buf := make([]byte, 100)
+		for {
+			n, err := resp.Body.Read(buf)
+			if n > 0 {
+				sizes = append(sizes, n)
+			}
+			if err == io.EOF {
+				break
+			}
+			td.Require(assert).CmpNoError(err)
+		}
+		assert.Cmp(clock.Now().Sub(before), 22*time.Millisecond)
+		assert.Cmp(sizes, []int{30, 10, 30, 10, 20})
+	})
+
+	assert.Run("bandwidth", func(assert *td.T) {
+		clock := httpmock.NewVirtualClock(time.Now())
+		mt := httpmock.NewMockTransport()
+		mt.SetClock(clock)
+		mt.RegisterResponder("GET", testURL,
+			httpmock.NewStringResponder(200, body).Throttle(httpmock.Throttling{
+				BytesPerSecond: 2000, // 100 bytes → 50ms
+				ChunkSize:      50,
+			}))
+
+		before := clock.Now()
+		resp, err := (&http.Client{Transport: mt}).Get(testURL)
+		td.Require(assert).CmpNoError(err)
+
+		go func() {
+			for i := 0; i < 2; i++ {
+				clock.BlockUntil(1)
+				clock.Advance(25 * time.Millisecond) // 50 bytes
+			}
+		}()
+		assertBody(assert, resp, body)
+		assert.Cmp(clock.Now().Sub(before), 50*time.Millisecond)
+	})
+
+	assert.Run("canceled", func(assert *td.T) {
+		r := httpmock.NewStringResponder(200, body).Throttle(httpmock.Throttling{
+			TimeToFirstByte: time.Hour,
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		resp := call(assert, r, ctx)
+		cancel()
+		_, err := ioutil.ReadAll(resp.Body)
+		assert.Cmp(err, context.Canceled)
+	})
+
+	assert.Run("profile", func(assert *td.T) {
+		clock := httpmock.NewVirtualClock(time.Now())
+		mt := httpmock.NewMockTransport()
+		mt.SetClock(clock)
+		mt.RegisterResponder("GET", testURL,
+			httpmock.NewStringResponder(200, body).ThrottleProfile("slow-3g"))
+
+		resp, err := (&http.Client{Transport: mt}).Get(testURL)
+		td.Require(assert).CmpNoError(err)
+
+		go func() {
+			clock.BlockUntil(1)
+			clock.Advance(2 * time.Second) // time to first byte
+			clock.BlockUntil(1)
+			clock.Advance(2 * time.Millisecond) // 100 bytes at 50 KB/s
+		}()
+		assertBody(assert, resp, body)
+
+		assert.CmpPanic(func() { httpmock.NewStringResponder(200, "").ThrottleProfile("unknown") },
+			`ThrottleProfile: unknown network profile "unknown"`)
+	})
+}
@@ -0,0 +1,123 @@
+package httpmock
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+)
+
+// trailerBody fills trailer once body reaches EOF, using fn called
+// with the whole content read.
+type trailerBody struct {
+	body    io.ReadCloser
+	trailer http.Header
+	fn      func(body []byte) http.Header
+	buf     bytes.Buffer
+	done    bool
+}
+
+func (b *trailerBody) Read(p []byte) (int, error) {
+	n, err := b.body.Read(p)
+	b.buf.Write(p[:n])
+	if err == io.EOF && !b.done {
+		b.done = true
+		for key, values := range b.fn(b.buf.Bytes()) {
+			key = http.CanonicalHeaderKey(key)
+			if _, ok := b.trailer[key]; ok {
+				b.trailer[key] = values
+			}
+		}
+		b.buf = bytes.Buffer{}
+	}
+	return n, err
+}
+
+func (b *trailerBody) Close() error {
+	return b.body.Close()
+}
+
+// Chunked returns a new [Responder] based on r whose responses use
+// the chunked transfer coding, as net/http reports them: the
+// TransferEncoding field is set to ["chunked"], the ContentLength
+// field to -1 and the Content-Length header is removed.
+//
+//	httpmock.RegisterResponder("GET", "/stream",
+//	  httpmock.NewStringResponder(200, "data").Chunked())
+func (r Responder) Chunked() Responder {
+	return func(req *http.Request) (*http.Response, error) {
+		resp, err := r(req)
+		if err != nil || resp == nil {
+			return resp, err
+		}
+		nr := *resp
+		nr.TransferEncoding = []string{"chunked"}
+		nr.ContentLength = -1
+		if nr.Header == nil {
+			nr.Header = http.Header{}
+		}
+		nr.Header = nr.Header.Clone()
+		nr.Header.Del("Content-Length")
+		return &nr, nil
+	}
+}
+
+// TrailerFunc returns a new [Responder] based on r whose responses
+// are chunked (see [Responder.Chunked]) and have trailers, as
+// net/http handles them: the Trailer field of the response contains
+// the announced names keys with nil values until the body is read
+// until EOF. At this time, fn is called with the whole body content
+// and the values it returns for announced names are set in the
+// Trailer field. Other returned keys are ignored.
+//
+// It allows to compute checksum trailers:
+//
+//	httpmock.RegisterResponder("GET", "/file",
+//	  httpmock.NewStringResponder(200, "content").
+//	    TrailerFunc([]string{"X-Checksum"}, func(body []byte) http.Header {
+//	      sum := sha256.Sum256(body)
+//	      return http.Header{"X-Checksum": {hex.EncodeToString(sum[:])}}
+//	    }))
+//
+// See also [Responder.Trailer].
+func (r Responder) TrailerFunc(names []string, fn func(body []byte) http.Header) Responder {
+	chunked := r.Chunked()
+	return func(req *http.Request) (*http.Response, error) {
+		resp, err := chunked(req)
+		if err != nil || resp == nil {
+			return resp, err
+		}
+		trailer := make(http.Header, len(names))
+		for _, name := range names {
+			trailer[http.CanonicalHeaderKey(name)] = nil
+		}
+		body := resp.Body
+		if body == nil {
+			body = http.NoBody
+		}
+		resp.Trailer = trailer
+		resp.Body = &trailerBody{body: body, trailer: trailer, fn: fn}
+		return resp, nil
+	}
+}
+
+// Trailer returns a new [Responder] based on r whose responses are
+// chunked (see [Responder.Chunked]) and have trailer as trailers, as
+// net/http handles them: the Trailer field of the response contains
+// the trailer keys with nil values until the body is read until EOF.
+// At this time, trailer values are set.
+//
+//	httpmock.RegisterResponder("POST", "/grpc.Service/Method",
+//	  httpmock.NewBytesResponder(200, payload).
+//	    Trailer(http.Header{"Grpc-Status": {"0"}, "Grpc-Message": {""}}))
+//
+// See also [Responder.TrailerFunc].
+func (r Responder) Trailer(trailer http.Header) Responder {
+	names := make([]string, 0, len(trailer))
+	for name := range trailer {
+		names = append(names, name)
+	}
+	trailer = trailer.Clone()
+	return r.TrailerFunc(names, func([]byte) http.Header {
+		return trailer.Clone()
+	})
+}
@@ -0,0 +1,79 @@
+package httpmock_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil" //nolint: staticcheck
+	"net/http"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/td"
+
+	"github.com/jarcoal/httpmock"
+)
+
+func TestResponderChunked(t *testing.T) {
+	assert, require := td.AssertRequire(t)
+
+	req, err := http.NewRequest("GET", "http://z.tld/", nil)
+	require.CmpNoError(err)
+
+	resp, err := httpmock.NewStringResponder(200, "data").SetContentLength().Chunked()(req)
+	require.CmpNoError(err)
+	assert.Cmp(resp.TransferEncoding, []string{"chunked"})
+	assert.Cmp(resp.ContentLength, int64(-1))
+	assert.Cmp(resp.Header.Get("Content-Length"), "")
+	assertBody(assert, resp, "data")
+}
+
+func TestResponderTrailer(t *testing.T) {
+	assert, require := td.AssertRequire(t)
+
+	mt := httpmock.NewMockTransport()
+	mt.RegisterResponder("POST", "http://z.tld/grpc",
+		httpmock.NewStringResponder(200, "payload").
+			Trailer(http.Header{"grpc-status": {"0"}, "Grpc-Message": {"OK"}}))
+	mt.RegisterResponder("GET", "http://z.tld/file",
+		httpmock.NewStringResponder(200, "content").
+			TrailerFunc([]string{"x-checksum"}, func(body []byte) http.Header {
+				sum := sha256.Sum256(body)
+				return http.Header{
+					"X-Checksum": {hex.EncodeToString(sum[:])},
+					"X-Ignored":  {"not announced"},
+				}
+			}))
+	client := &http.Client{Transport: mt}
+
+	resp, err := client.Post("http://z.tld/grpc", "application/grpc", nil)
+	require.CmpNoError(err)
+	assert.Cmp(resp.TransferEncoding, []string{"chunked"})
+	assert.Cmp(resp.ContentLength, int64(-1))
+
+	// Announced, but not available before EOF
+	assert.Cmp(resp.Trailer, http.Header{"Grpc-Status": nil, "Grpc-Message": nil})
+
+	buf := make([]byte, 3)
+	_, err = io.ReadFull(resp.Body, buf)
+	require.CmpNoError(err)
+	assert.Cmp(resp.Trailer.Get("Grpc-Status"), "")
+
+	b, err := ioutil.ReadAll(resp.Body)
+	require.CmpNoError(err)
+	assert.Cmp(string(buf)+string(b), "payload")
+	assert.Cmp(resp.Trailer, http.Header{"Grpc-Status": {"0"}, "Grpc-Message": {"OK"}})
+
+	// Each response has its own trailer
+	resp2, err := client.Post("http://z.tld/grpc", "application/grpc", nil)
+	require.CmpNoError(err)
+	assert.Cmp(resp2.Trailer, http.Header{"Grpc-Status": nil, "Grpc-Message": nil})
+	resp2.Body.Close()
+
+	// Computed from the body
+	resp, err = client.Get("http://z.tld/file")
+	require.CmpNoError(err)
+	assert.Cmp(resp.Trailer, http.Header{"X-Checksum": nil})
+	assertBody(assert, resp, "content")
+	sum := sha256.Sum256([]byte("content"))
+	assert.Cmp(resp.Trailer, http.Header{"X-Checksum": {hex.EncodeToString(sum[:])}})
+}
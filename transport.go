@@ -77,6 +77,11 @@ type MockTransport struct {
 	noResponder      Responder
 	callCountInfo    map[matchRouteKey]int
 	totalCallCount   int
+	faults           *faultInjector
+	clock            Clock
+	trackBodies      bool
+	bodies           []*trackedBody
+	methodFallback   bool
 }
 
 var findForKey = []func(*MockTransport, internal.RouteKey) respondersFound{
@@ -244,6 +249,14 @@ func (m *MockTransport) suggestResponder(method string, url *url.URL) *internal.
 // interface.  You will not interact with this directly, instead the
 // [*http.Client] you are using will call it for you.
 func (m *MockTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := m.roundTrip(req)
+	if err == nil {
+		resp = m.trackBody(req, resp)
+	}
+	return resp, err
+}
+
+func (m *MockTransport) roundTrip(req *http.Request) (*http.Response, error) {
 	method := req.Method
 	if method == "" {
 		// http.Request.Method is documented to default to GET:
@@ -278,10 +291,18 @@ func (m *MockTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 				// a suggestion is not already available, do it now
 				fail = true
 
+				closest, diff := func() (string, string) {
+					m.mu.RLock()
+					defer m.mu.RUnlock()
+					return found.responders.closest(req)
+				}()
+
 				if len(found.responders) == 1 {
 					suggested = &internal.ErrorNoResponderFoundMistake{
 						Kind:      "matcher",
 						Suggested: fmt.Sprintf("matcher %q", found.responders[0].matcher.name),
+						Closest:   closest,
+						Diff:      diff,
 					}
 				} else {
 					names := make([]string, len(found.responders))
@@ -291,6 +312,8 @@ func (m *MockTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 					suggested = &internal.ErrorNoResponderFoundMistake{
 						Kind:      "matcher",
 						Suggested: fmt.Sprintf("%d matchers: %q", len(found.responders), names),
+						Closest:   closest,
+						Diff:      diff,
 					}
 				}
 			}
@@ -317,6 +340,24 @@ func (m *MockTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 		break
 	}
 
+	if fail && m.methodFallbackEnabled() {
+		allowed := m.allowedMethods(req.URL)
+		switch {
+		case allowed[method]:
+			// responders exist for this method, but none matches
+		case method == http.MethodHead && allowed[http.MethodGet]:
+			return m.headFromGet(req)
+		case len(allowed) > 0:
+			fail = false
+			responder = newMethodNotAllowedResponder(allowed)
+
+			m.mu.Lock()
+			m.callCountInfo[matchRouteKey{RouteKey: internal.NoResponder}]++
+			m.totalCallCount++
+			m.mu.Unlock()
+		}
+	}
+
 	if fail {
 		m.mu.Lock()
 		if m.noResponder != nil {
@@ -330,6 +371,8 @@ func (m *MockTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 				req = req.WithContext(context.WithValue(req.Context(), suggestedKey, &suggestedInfo{
 					kind:      suggested.Kind,
 					suggested: suggested.Suggested,
+					closest:   suggested.Closest,
+					diff:      suggested.Diff,
 				}))
 			}
 			responder = m.noResponder
@@ -343,6 +386,16 @@ func (m *MockTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 		}
 		return ConnectionFailure(req)
 	}
+
+	m.mu.RLock()
+	if m.faults != nil {
+		responder = m.faults.wrap(responder)
+	}
+	if m.clock != nil {
+		req = req.WithContext(context.WithValue(req.Context(), clockKey, m.clock))
+	}
+	m.mu.RUnlock()
+
 	return runCancelable(responder, internal.SetSubmatches(req, found.submatches))
 }
 
@@ -874,29 +927,7 @@ func (m *MockTransport) RegisterMatcherResponderWithQuery(method, path string, q
 		panic(`path begins with "=~", RegisterResponder should be used instead of RegisterResponderWithQuery`)
 	}
 
-	var mapQuery url.Values
-	switch q := query.(type) {
-	case url.Values:
-		mapQuery = q
-
-	case map[string]string:
-		mapQuery = make(url.Values, len(q))
-		for key, e := range q {
-			mapQuery[key] = []string{e}
-		}
-
-	case string:
-		var err error
-		mapQuery, err = url.ParseQuery(q)
-		if err != nil {
-			panic("RegisterResponderWithQuery bad query string: " + err.Error())
-		}
-
-	default:
-		if query != nil {
-			panic(fmt.Sprintf("RegisterResponderWithQuery bad query type %T. Only url.Values, map[string]string and string are allowed", query))
-		}
-	}
+	mapQuery := queryValues("RegisterResponderWithQuery", query)
 
 	if queryString := sortedQuery(mapQuery); queryString != "" {
 		path += "?" + queryString
@@ -939,6 +970,37 @@ func (m *MockTransport) RegisterResponderWithQuery(method, path string, query an
 	m.RegisterMatcherResponderWithQuery(method, path, query, Matcher{}, responder)
 }
 
+// queryValues converts query to [url.Values]. query can be nil,
+// [url.Values], map[string]string or a query string. Any other type
+// or a bad query string triggers a panic whose message is prefixed
+// by caller.
+func queryValues(caller string, query any) url.Values {
+	switch q := query.(type) {
+	case url.Values:
+		return q
+
+	case map[string]string:
+		mapQuery := make(url.Values, len(q))
+		for key, e := range q {
+			mapQuery[key] = []string{e}
+		}
+		return mapQuery
+
+	case string:
+		mapQuery, err := url.ParseQuery(q)
+		if err != nil {
+			panic(caller + " bad query string: " + err.Error())
+		}
+		return mapQuery
+
+	default:
+		if query != nil {
+			panic(fmt.Sprintf("%s bad query type %T. Only url.Values, map[string]string and string are allowed", caller, query))
+		}
+		return nil
+	}
+}
+
 func sortedQuery(m url.Values) string {
 	if len(m) == 0 {
 		return ""
@@ -1013,15 +1075,79 @@ func (m *MockTransport) RegisterNoResponder(responder Responder) {
 	m.mu.Unlock()
 }
 
+// SetFaults injects faults in all responders of m, including the one
+// registered with [MockTransport.RegisterNoResponder]. See
+// [Responder.WithFaults] for details on seed and faults. The call
+// numbers of faults Calls field are counted across all responders,
+// since this call.
+//
+// Calling SetFaults without any fault disables the fault injection.
+func (m *MockTransport) SetFaults(seed int64, faults ...Fault) {
+	m.mu.Lock()
+	if len(faults) == 0 {
+		m.faults = nil
+	} else {
+		m.faults = newFaultInjector(seed, faults)
+	}
+	m.mu.Unlock()
+}
+
+// RegisterOAuth2Provider registers the responders of the fake
+// authorization server p, under p.Issuer URL. See [OAuth2Provider]
+// for the list of endpoints.
+//
+//	provider := httpmock.NewOAuth2Provider("https://auth.z.tld",
+//	  map[string]string{"my-client": "my-secret"})
+//	mt.RegisterOAuth2Provider(provider)
+func (m *MockTransport) RegisterOAuth2Provider(p *OAuth2Provider) {
+	m.RegisterResponder(http.MethodGet, p.Issuer+"/.well-known/openid-configuration", p.discovery)
+	m.RegisterResponder(http.MethodGet, p.Issuer+"/.well-known/jwks.json", p.jwks)
+	m.RegisterResponder(http.MethodGet, p.Issuer+"/authorize", p.authorize)
+	m.RegisterResponder(http.MethodPost, p.Issuer+"/token", p.token)
+	m.RegisterResponder(http.MethodPost, p.Issuer+"/introspect", p.introspect)
+}
+
+// RegisterRedirectChain registers the responders of each URL of
+// chain, for the GET, HEAD, POST, PUT, PATCH, DELETE and OPTIONS
+// methods, as the method can change while following redirections.
+// See [NewRedirectChain].
+func (m *MockTransport) RegisterRedirectChain(chain *RedirectChain) {
+	for i, step := range chain.steps {
+		responder := chain.responder(i)
+		for _, method := range redirectMethods {
+			m.RegisterResponder(method, step.url, responder)
+		}
+	}
+}
+
+// SetClock sets the [Clock] used by time-based responders called by
+// m, like the ones returned by [Responder.Delay],
+// [Responder.RateLimit] or [Responder.WithFaults]. Typically a
+// [*VirtualClock] is used, so tests simulating slow APIs do not
+// really wait.
+//
+// If clock is nil, [SystemClock] is used.
+func (m *MockTransport) SetClock(clock Clock) {
+	m.mu.Lock()
+	m.clock = clock
+	m.mu.Unlock()
+}
+
 // Reset removes all registered responders (including the no
-// responder) from the [MockTransport]. It zeroes call counters too.
+// responder) and injected faults from the [MockTransport]. It zeroes
+// call counters and forgets tracked response bodies too. The clock set
+// by [MockTransport.SetClock], the body tracking mode set by
+// [MockTransport.TrackBodies] and the method fallback mode set by
+// [MockTransport.SetMethodFallback] are kept.
 func (m *MockTransport) Reset() {
 	m.mu.Lock()
 	m.responders = make(map[internal.RouteKey]matchResponders)
 	m.regexpResponders = nil
 	m.noResponder = nil
+	m.faults = nil
 	m.callCountInfo = make(map[matchRouteKey]int)
 	m.totalCallCount = 0
+	m.bodies = nil
 	m.mu.Unlock()
 }
 
@@ -1640,6 +1766,69 @@ func RegisterNoResponder(responder Responder) {
 	DefaultTransport.RegisterNoResponder(responder)
 }
 
+// SetFaults injects faults in all responders of [DefaultTransport],
+// including the one registered with [RegisterNoResponder]. See
+// [Responder.WithFaults] for details on seed and faults. The call
+// numbers of faults Calls field are counted across all responders,
+// since this call.
+//
+// Calling SetFaults without any fault disables the fault injection.
+func SetFaults(seed int64, faults ...Fault) {
+	DefaultTransport.SetFaults(seed, faults...)
+}
+
+// RegisterOAuth2Provider registers the responders of the fake
+// authorization server p, under p.Issuer URL, on
+// [DefaultTransport]. See [MockTransport.RegisterOAuth2Provider].
+func RegisterOAuth2Provider(p *OAuth2Provider) {
+	DefaultTransport.RegisterOAuth2Provider(p)
+}
+
+// RegisterRedirectChain registers the responders of each URL of
+// chain on [DefaultTransport]. See
+// [MockTransport.RegisterRedirectChain].
+func RegisterRedirectChain(chain *RedirectChain) {
+	DefaultTransport.RegisterRedirectChain(chain)
+}
+
+// TrackBodies enables or disables the tracking of response bodies
+// handed out by [DefaultTransport]. See [MockTransport.TrackBodies].
+func TrackBodies(track bool) {
+	DefaultTransport.TrackBodies(track)
+}
+
+// LeakedBodies returns the response bodies handed out by
+// [DefaultTransport] not closed or not read until EOF. See
+// [MockTransport.LeakedBodies].
+func LeakedBodies() []LeakedBody {
+	return DefaultTransport.LeakedBodies()
+}
+
+// CheckBodies returns an error listing the response bodies handed out
+// by [DefaultTransport] not closed or not read until EOF. See
+// [MockTransport.CheckBodies].
+func CheckBodies() error {
+	return DefaultTransport.CheckBodies()
+}
+
+// SetMethodFallback enables or disables the automatic HEAD and 405
+// Method Not Allowed mode of [DefaultTransport]. See
+// [MockTransport.SetMethodFallback].
+func SetMethodFallback(enable bool) {
+	DefaultTransport.SetMethodFallback(enable)
+}
+
+// SetClock sets the [Clock] used by time-based responders called by
+// [DefaultTransport], like the ones returned by [Responder.Delay],
+// [Responder.RateLimit] or [Responder.WithFaults]. Typically a
+// [*VirtualClock] is used, so tests simulating slow APIs do not
+// really wait.
+//
+// If clock is nil, [SystemClock] is used.
+func SetClock(clock Clock) {
+	DefaultTransport.SetClock(clock)
+}
+
 // ErrSubmatchNotFound is the error returned by GetSubmatch* functions
 // when the given submatch index cannot be found.
 var ErrSubmatchNotFound = errors.New("submatch not found")
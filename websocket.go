@@ -0,0 +1,367 @@
+package httpmock
+
+import (
+	"crypto/sha1" //nolint: gosec
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// websocketGUID is the magic value used to compute
+// Sec-WebSocket-Accept, see RFC 6455 section 1.3.
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// maxWebSocketFrameSize is the maximum payload length of a frame read
+// by [WebSocketPeer.ReadFrame].
+const maxWebSocketFrameSize = 32 << 20
+
+// WebSocketOpcode is the opcode of a [WebSocketFrame], as defined in
+// RFC 6455 section 5.2.
+type WebSocketOpcode byte
+
+// WebSocket opcodes.
+const (
+	WebSocketContinuation WebSocketOpcode = 0x0
+	WebSocketText         WebSocketOpcode = 0x1
+	WebSocketBinary       WebSocketOpcode = 0x2
+	WebSocketClose        WebSocketOpcode = 0x8
+	WebSocketPing         WebSocketOpcode = 0x9
+	WebSocketPong         WebSocketOpcode = 0xa
+)
+
+// WebSocketFrame is a WebSocket frame read or written by a
+// [WebSocketPeer].
+type WebSocketFrame struct {
+	Fin     bool
+	Opcode  WebSocketOpcode
+	Payload []byte
+}
+
+// WebSocketCloseError is returned by [WebSocketPeer.ReadMessage] when
+// the client sends a close frame.
+type WebSocketCloseError struct {
+	Code   int
+	Reason string
+}
+
+// Error implements error interface.
+func (e *WebSocketCloseError) Error() string {
+	return fmt.Sprintf("websocket closed by client: %d %s", e.Code, e.Reason)
+}
+
+// WebSocketAccept returns the Sec-WebSocket-Accept header value
+// corresponding to the Sec-WebSocket-Key header value key.
+func WebSocketAccept(key string) string {
+	h := sha1.Sum([]byte(key + websocketGUID)) //nolint: gosec
+	return base64.StdEncoding.EncodeToString(h[:])
+}
+
+// WebSocketPeer is the server side of a WebSocket connection
+// established by a [Responder] returned by [NewWebSocketResponder].
+// Frames written by the server are not masked, frames read from the
+// client are unmasked.
+type WebSocketPeer struct {
+	// Request is the request that initiated the connection.
+	Request *http.Request
+	// Subprotocol is the negotiated subprotocol, if any.
+	Subprotocol string
+
+	conn     net.Conn
+	mu       sync.Mutex // protects writes
+	closeSnt bool
+}
+
+// Conn returns the underlying in-memory connection, allowing to
+// write raw bytes, for example to inject malformed frames, or to set
+// deadlines.
+func (p *WebSocketPeer) Conn() net.Conn {
+	return p.conn
+}
+
+// Abort abruptly closes the connection, without any close frame. The
+// client typically gets an [io.EOF] or [io.ErrUnexpectedEOF] error.
+func (p *WebSocketPeer) Abort() error {
+	return p.conn.Close()
+}
+
+// ReadFrame reads the next frame sent by the client. An error is
+// returned if the frame payload length exceeds 32 MiB.
+func (p *WebSocketPeer) ReadFrame() (WebSocketFrame, error) {
+	var hdr [2]byte
+	if _, err := io.ReadFull(p.conn, hdr[:]); err != nil {
+		return WebSocketFrame{}, err
+	}
+	f := WebSocketFrame{
+		Fin:    hdr[0]&0x80 != 0,
+		Opcode: WebSocketOpcode(hdr[0] & 0x0f),
+	}
+
+	length := uint64(hdr[1] & 0x7f)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(p.conn, ext[:]); err != nil {
+			return f, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(p.conn, ext[:]); err != nil {
+			return f, err
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	if length > maxWebSocketFrameSize {
+		return f, fmt.Errorf("websocket: frame payload length %d exceeds %d bytes", length, maxWebSocketFrameSize)
+	}
+
+	var mask [4]byte
+	masked := hdr[1]&0x80 != 0
+	if masked {
+		if _, err := io.ReadFull(p.conn, mask[:]); err != nil {
+			return f, err
+		}
+	}
+
+	f.Payload = make([]byte, length)
+	if _, err := io.ReadFull(p.conn, f.Payload); err != nil {
+		return f, err
+	}
+	if masked {
+		for i := range f.Payload {
+			f.Payload[i] ^= mask[i%4]
+		}
+	}
+	return f, nil
+}
+
+// WriteFrame writes f to the client. It allows to send fragmented
+// messages or unexpected frames.
+func (p *WebSocketPeer) WriteFrame(f WebSocketFrame) error {
+	b := make([]byte, 2, 10+len(f.Payload))
+	b[0] = byte(f.Opcode & 0x0f)
+	if f.Fin {
+		b[0] |= 0x80
+	}
+	switch l := len(f.Payload); {
+	case l < 126:
+		b[1] = byte(l)
+	case l <= 0xffff:
+		b[1] = 126
+		b = append(b, byte(l>>8), byte(l))
+	default:
+		b[1] = 127
+		var ext [8]byte
+		binary.BigEndian.PutUint64(ext[:], uint64(l))
+		b = append(b, ext[:]...)
+	}
+	b = append(b, f.Payload...)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, err := p.conn.Write(b)
+	return err
+}
+
+// ReadMessage reads the next text or binary message sent by the
+// client, reassembling fragmented messages. Ping frames are
+// automatically answered by a pong frame and pong frames are
+// ignored. If the client sends a close frame, a close frame is sent
+// back (unless [WebSocketPeer.Close] has already been called) and a
+// [*WebSocketCloseError] is returned. If the client close frame has
+// no status code, the returned error Code is 1005 and the close frame
+// sent back is empty, as 1005 must not be sent.
+func (p *WebSocketPeer) ReadMessage() (WebSocketOpcode, []byte, error) {
+	var (
+		opcode WebSocketOpcode
+		data   []byte
+	)
+	for {
+		f, err := p.ReadFrame()
+		if err != nil {
+			return 0, nil, err
+		}
+
+		switch f.Opcode {
+		case WebSocketPing:
+			if err := p.WriteFrame(WebSocketFrame{Fin: true, Opcode: WebSocketPong, Payload: f.Payload}); err != nil {
+				return 0, nil, err
+			}
+			continue
+
+		case WebSocketPong:
+			continue
+
+		case WebSocketClose:
+			cerr := &WebSocketCloseError{Code: 1005}
+			if len(f.Payload) >= 2 {
+				cerr.Code = int(binary.BigEndian.Uint16(f.Payload))
+				cerr.Reason = string(f.Payload[2:])
+			}
+			p.mu.Lock()
+			sent := p.closeSnt
+			p.mu.Unlock()
+			if !sent {
+				if len(f.Payload) >= 2 {
+					p.Close(cerr.Code, "") //nolint: errcheck
+				} else {
+					// 1005 must not be sent, so reply with an empty close frame
+					p.writeClose(nil) //nolint: errcheck
+				}
+			}
+			return 0, nil, cerr
+
+		case WebSocketContinuation:
+			if opcode == 0 {
+				return 0, nil, errors.New("websocket: unexpected continuation frame")
+			}
+
+		default:
+			if opcode != 0 {
+				return 0, nil, errors.New("websocket: expected continuation frame")
+			}
+			opcode = f.Opcode
+		}
+
+		data = append(data, f.Payload...)
+		if f.Fin {
+			return opcode, data, nil
+		}
+	}
+}
+
+// WriteText sends a text message to the client.
+func (p *WebSocketPeer) WriteText(s string) error {
+	return p.WriteFrame(WebSocketFrame{Fin: true, Opcode: WebSocketText, Payload: []byte(s)})
+}
+
+// WriteBinary sends a binary message to the client.
+func (p *WebSocketPeer) WriteBinary(b []byte) error {
+	return p.WriteFrame(WebSocketFrame{Fin: true, Opcode: WebSocketBinary, Payload: b})
+}
+
+// Ping sends a ping frame to the client.
+func (p *WebSocketPeer) Ping(payload []byte) error {
+	return p.WriteFrame(WebSocketFrame{Fin: true, Opcode: WebSocketPing, Payload: payload})
+}
+
+// Close sends a close frame with code and reason to the client. The
+// connection is not closed, so the close frame of the client can
+// still be read using [WebSocketPeer.ReadFrame] or
+// [WebSocketPeer.ReadMessage].
+func (p *WebSocketPeer) Close(code int, reason string) error {
+	payload := make([]byte, 2, 2+len(reason))
+	binary.BigEndian.PutUint16(payload, uint16(code))
+	payload = append(payload, reason...)
+	return p.writeClose(payload)
+}
+
+func (p *WebSocketPeer) writeClose(payload []byte) error {
+	p.mu.Lock()
+	p.closeSnt = true
+	p.mu.Unlock()
+	return p.WriteFrame(WebSocketFrame{Fin: true, Opcode: WebSocketClose, Payload: payload})
+}
+
+func headerContainsToken(h http.Header, key, token string) bool {
+	for _, v := range h[http.CanonicalHeaderKey(key)] {
+		for _, t := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// NewWebSocketResponder creates a [Responder] completing the WebSocket
+// opening handshake described in RFC 6455. If the request is a valid
+// WebSocket upgrade request, a 101 Switching Protocols response is
+// returned, with the correct Sec-WebSocket-Accept header. As
+// net/http does for such responses, its body is an
+// [io.ReadWriteCloser], here the client side of an in-memory
+// [net.Pipe] connection. handler is then called in a new goroutine
+// with the server side of the connection, allowing the test to
+// script the peer. The connection is closed when handler returns.
+//
+// If the client requested subprotocols with the
+// Sec-WebSocket-Protocol header, the first one also present in
+// subprotocols is selected and sent back.
+//
+// If the request is not a valid upgrade request, a 400 Bad Request
+// response is returned. If its Sec-WebSocket-Version header is not
+// 13, a 426 Upgrade Required response is returned.
+//
+//	httpmock.RegisterResponder("GET", "wss://z.tld/ws",
+//	  httpmock.NewWebSocketResponder(func(peer *httpmock.WebSocketPeer) {
+//	    _, msg, err := peer.ReadMessage()
+//	    if err != nil {
+//	      return
+//	    }
+//	    peer.WriteText("echo: " + string(msg))
+//	    peer.Close(1000, "bye")
+//	  }))
+func NewWebSocketResponder(handler func(peer *WebSocketPeer), subprotocols ...string) Responder {
+	return func(req *http.Request) (*http.Response, error) {
+		key := req.Header.Get("Sec-WebSocket-Key")
+		if req.Method != http.MethodGet ||
+			!headerContainsToken(req.Header, "Connection", "upgrade") ||
+			!headerContainsToken(req.Header, "Upgrade", "websocket") ||
+			key == "" {
+			resp := NewStringResponse(http.StatusBadRequest, "Bad WebSocket upgrade request")
+			resp.Request = req
+			return resp, nil
+		}
+		if req.Header.Get("Sec-WebSocket-Version") != "13" {
+			resp := NewStringResponse(http.StatusUpgradeRequired, "Unsupported WebSocket version")
+			resp.Header.Set("Sec-WebSocket-Version", "13")
+			resp.Request = req
+			return resp, nil
+		}
+
+		client, server := net.Pipe()
+		peer := &WebSocketPeer{Request: req, conn: server}
+
+		header := http.Header{
+			"Upgrade":              {"websocket"},
+			"Connection":           {"Upgrade"},
+			"Sec-Websocket-Accept": {WebSocketAccept(key)},
+		}
+	proto:
+		for _, v := range req.Header["Sec-Websocket-Protocol"] {
+			for _, p := range strings.Split(v, ",") {
+				p = strings.TrimSpace(p)
+				for _, sp := range subprotocols {
+					if p == sp {
+						peer.Subprotocol = p
+						header.Set("Sec-WebSocket-Protocol", p)
+						break proto
+					}
+				}
+			}
+		}
+
+		go func() {
+			defer server.Close()
+			handler(peer)
+		}()
+
+		return &http.Response{
+			Status:     strconv.Itoa(http.StatusSwitchingProtocols),
+			StatusCode: http.StatusSwitchingProtocols,
+			Proto:      "HTTP/1.1",
+			ProtoMajor: 1,
+			ProtoMinor: 1,
+			Header:     header,
+			Body:       client,
+			Request:    req,
+		}, nil
+	}
+}
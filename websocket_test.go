@@ -0,0 +1,223 @@
+package httpmock_test
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"io/ioutil" //nolint: staticcheck
+	"net/http"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/td"
+
+	"github.com/jarcoal/httpmock"
+)
+
+// writeClientFrame writes a masked frame, as a client has to.
+func writeClientFrame(t testing.TB, w io.Writer, fin bool, opcode httpmock.WebSocketOpcode, payload []byte) {
+	t.Helper()
+	b0 := byte(opcode)
+	if fin {
+		b0 |= 0x80
+	}
+	mask := []byte{1, 2, 3, 4}
+	frame := []byte{b0, 0x80 | byte(len(payload))}
+	frame = append(frame, mask...)
+	for i, c := range payload {
+		frame = append(frame, c^mask[i%4])
+	}
+	_, err := w.Write(frame)
+	td.Require(t).CmpNoError(err)
+}
+
+// readServerFrame reads an unmasked frame of less than 126 bytes.
+func readServerFrame(t testing.TB, r io.Reader) (bool, httpmock.WebSocketOpcode, []byte) {
+	t.Helper()
+	var hdr [2]byte
+	_, err := io.ReadFull(r, hdr[:])
+	td.Require(t).CmpNoError(err)
+	payload := make([]byte, hdr[1])
+	_, err = io.ReadFull(r, payload)
+	td.Require(t).CmpNoError(err)
+	return hdr[0]&0x80 != 0, httpmock.WebSocketOpcode(hdr[0] & 0xf), payload
+}
+
+func TestWebSocketAccept(t *testing.T) {
+	// Example from RFC 6455 section 1.3
+	td.Cmp(t, httpmock.WebSocketAccept("dGhlIHNhbXBsZSBub25jZQ=="), "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=")
+}
+
+func TestNewWebSocketResponder(t *testing.T) {
+	assert, require := td.AssertRequire(t)
+
+	mt := httpmock.NewMockTransport()
+	client := &http.Client{Transport: mt}
+
+	done := make(chan error, 1)
+	mt.RegisterResponder("GET", "http://z.tld/ws",
+		httpmock.NewWebSocketResponder(func(peer *httpmock.WebSocketPeer) {
+			assert.Cmp(peer.Subprotocol, "chat")
+
+			opcode, msg, err := peer.ReadMessage()
+			if err != nil {
+				done <- err
+				return
+			}
+			assert.Cmp(opcode, httpmock.WebSocketText)
+			peer.WriteText("echo: " + string(msg)) //nolint: errcheck
+
+			_, _, err = peer.ReadMessage()
+			done <- err
+		}, "superchat", "chat"))
+
+	newReq := func() *http.Request {
+		req, err := http.NewRequest("GET", "http://z.tld/ws", nil)
+		require.CmpNoError(err)
+		req.Header.Set("Connection", "Upgrade")
+		req.Header.Set("Upgrade", "websocket")
+		req.Header.Set("Sec-WebSocket-Version", "13")
+		req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+		req.Header.Set("Sec-WebSocket-Protocol", "chat, superchat")
+		return req
+	}
+
+	resp, err := client.Do(newReq())
+	require.CmpNoError(err)
+	assert.Cmp(resp.StatusCode, http.StatusSwitchingProtocols)
+	assert.Cmp(resp.Header.Get("Sec-WebSocket-Accept"), "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=")
+	assert.Cmp(resp.Header.Get("Sec-WebSocket-Protocol"), "chat")
+
+	conn, ok := resp.Body.(io.ReadWriteCloser)
+	require.True(ok)
+	br := bufio.NewReader(conn)
+
+	// Fragmented message with a ping in the middle
+	writeClientFrame(t, conn, false, httpmock.WebSocketText, []byte("hel"))
+	writeClientFrame(t, conn, true, httpmock.WebSocketPing, []byte("p"))
+	fin, opcode, payload := readServerFrame(t, br)
+	assert.True(fin)
+	assert.Cmp(opcode, httpmock.WebSocketPong)
+	assert.Cmp(string(payload), "p")
+	writeClientFrame(t, conn, true, httpmock.WebSocketContinuation, []byte("lo"))
+
+	_, opcode, payload = readServerFrame(t, br)
+	assert.Cmp(opcode, httpmock.WebSocketText)
+	assert.Cmp(string(payload), "echo: hello")
+
+	// Client closes
+	closePayload := make([]byte, 2, 5)
+	binary.BigEndian.PutUint16(closePayload, 1001)
+	writeClientFrame(t, conn, true, httpmock.WebSocketClose, append(closePayload, "bye"...))
+	_, opcode, payload = readServerFrame(t, br)
+	assert.Cmp(opcode, httpmock.WebSocketClose)
+	assert.Cmp(binary.BigEndian.Uint16(payload), uint16(1001))
+
+	assert.Cmp(<-done, &httpmock.WebSocketCloseError{Code: 1001, Reason: "bye"})
+	assert.String(&httpmock.WebSocketCloseError{Code: 1001, Reason: "bye"},
+		"websocket closed by client: 1001 bye")
+
+	// Handler returned, so connection is closed
+	_, err = br.ReadByte()
+	assert.Cmp(err, io.EOF)
+	conn.Close()
+
+	// Bad requests
+	req := newReq()
+	req.Header.Del("Upgrade")
+	resp, err = client.Do(req)
+	require.CmpNoError(err)
+	assert.Cmp(resp.StatusCode, http.StatusBadRequest)
+
+	req = newReq()
+	req.Header.Set("Sec-WebSocket-Version", "8")
+	resp, err = client.Do(req)
+	require.CmpNoError(err)
+	assert.Cmp(resp.StatusCode, http.StatusUpgradeRequired)
+	assert.Cmp(resp.Header.Get("Sec-WebSocket-Version"), "13")
+}
+
+func TestWebSocketPeerAbort(t *testing.T) {
+	assert, require := td.AssertRequire(t)
+
+	responder := httpmock.NewWebSocketResponder(func(peer *httpmock.WebSocketPeer) {
+		peer.WriteBinary(make([]byte, 300)) //nolint: errcheck
+		peer.Abort()                        //nolint: errcheck
+	})
+
+	req, err := http.NewRequest("GET", "http://z.tld/ws", nil)
+	require.CmpNoError(err)
+	req.Header.Set("Connection", "keep-alive, Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", "x")
+
+	resp, err := responder(req)
+	require.CmpNoError(err)
+	assert.Cmp(resp.StatusCode, http.StatusSwitchingProtocols)
+	assert.Cmp(resp.Header, td.Not(td.ContainsKey("Sec-Websocket-Protocol")))
+
+	var hdr [4]byte
+	_, err = io.ReadFull(resp.Body, hdr[:])
+	require.CmpNoError(err)
+	assert.Cmp(hdr, [4]byte{0x82, 126, 0x01, 0x2c})
+
+	_, err = ioutil.ReadAll(resp.Body)
+	assert.CmpNoError(err)
+}
+
+func TestWebSocketPeerReadFrameTooLarge(t *testing.T) {
+	assert, require := td.AssertRequire(t)
+
+	errc := make(chan error, 1)
+	responder := httpmock.NewWebSocketResponder(func(peer *httpmock.WebSocketPeer) {
+		_, err := peer.ReadFrame()
+		errc <- err
+	})
+
+	req, err := http.NewRequest("GET", "http://z.tld/ws", nil)
+	require.CmpNoError(err)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", "x")
+
+	resp, err := responder(req)
+	require.CmpNoError(err)
+	defer resp.Body.Close()
+
+	// 64-bit length with the high bit set
+	frame := []byte{0x82, 0x80 | 127, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+	_, err = resp.Body.(io.Writer).Write(frame)
+	require.CmpNoError(err)
+	assert.String(<-errc,
+		"websocket: frame payload length 18446744073709551615 exceeds 33554432 bytes")
+}
+
+func TestWebSocketPeerCloseWithoutStatus(t *testing.T) {
+	assert, require := td.AssertRequire(t)
+
+	done := make(chan error, 1)
+	responder := httpmock.NewWebSocketResponder(func(peer *httpmock.WebSocketPeer) {
+		_, _, err := peer.ReadMessage()
+		done <- err
+	})
+
+	req, err := http.NewRequest("GET", "http://z.tld/ws", nil)
+	require.CmpNoError(err)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", "x")
+
+	resp, err := responder(req)
+	require.CmpNoError(err)
+	defer resp.Body.Close()
+	conn := resp.Body.(io.ReadWriteCloser)
+
+	writeClientFrame(t, conn, true, httpmock.WebSocketClose, nil)
+	fin, opcode, payload := readServerFrame(t, conn)
+	assert.True(fin)
+	assert.Cmp(opcode, httpmock.WebSocketClose)
+	assert.Len(payload, 0)
+	assert.Cmp(<-done, &httpmock.WebSocketCloseError{Code: 1005})
+}
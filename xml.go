@@ -0,0 +1,452 @@
+package httpmock
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// xmlNode is a namespace-aware XML element, without insignificant
+// whitespaces, comments, processing instructions nor namespace
+// declarations.
+type xmlNode struct {
+	name     xml.Name // Space is the namespace URI, not the prefix
+	attrs    []xml.Attr
+	children []*xmlNode
+	text     string
+}
+
+func xmlNameString(n xml.Name) string {
+	if n.Space == "" {
+		return n.Local
+	}
+	return "{" + n.Space + "}" + n.Local
+}
+
+// decodeXML decodes b as a tree of xmlNode and returns its root.
+func decodeXML(b []byte) (*xmlNode, error) {
+	var (
+		root  *xmlNode
+		stack []*xmlNode
+		texts []*strings.Builder
+	)
+	dec := xml.NewDecoder(bytes.NewReader(b))
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if root != nil && len(stack) == 0 {
+				return nil, errors.New("XML syntax error: several root elements")
+			}
+			node := &xmlNode{name: t.Name}
+			for _, attr := range t.Attr {
+				if attr.Name.Space == "xmlns" || (attr.Name.Space == "" && attr.Name.Local == "xmlns") {
+					continue // namespace declaration
+				}
+				node.attrs = append(node.attrs, attr)
+			}
+			sort.Slice(node.attrs, func(i, j int) bool {
+				return xmlNameString(node.attrs[i].Name) < xmlNameString(node.attrs[j].Name)
+			})
+			if len(stack) == 0 {
+				root = node
+			} else {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, node)
+			}
+			stack = append(stack, node)
+			texts = append(texts, &strings.Builder{})
+
+		case xml.EndElement:
+			node := stack[len(stack)-1]
+			node.text = strings.TrimSpace(texts[len(texts)-1].String())
+			stack, texts = stack[:len(stack)-1], texts[:len(texts)-1]
+
+		case xml.CharData:
+			if len(stack) > 0 {
+				texts[len(texts)-1].Write(t)
+			} else if len(bytes.TrimSpace(t)) > 0 {
+				return nil, errors.New("XML syntax error: text outside root element")
+			}
+		}
+	}
+	if root == nil {
+		return nil, errors.New("XML syntax error: no root element")
+	}
+	return root, nil
+}
+
+// xmlChildPath returns the path of the i-th child of parent, adding
+// its position only if parent has several children with the same name.
+func xmlChildPath(path string, parent *xmlNode, i int) string {
+	child := parent.children[i]
+	pos, count := 0, 0
+	for j, c := range parent.children {
+		if c.name == child.name {
+			count++
+			if j <= i {
+				pos++
+			}
+		}
+	}
+	path += "/" + xmlNameString(child.name)
+	if count > 1 {
+		path += "[" + strconv.Itoa(pos) + "]"
+	}
+	return path
+}
+
+// size returns the number of elements and attributes of the tree
+// rooted at n.
+func (n *xmlNode) size() int {
+	size := 1 + len(n.attrs)
+	for _, c := range n.children {
+		size += c.size()
+	}
+	return size
+}
+
+// xmlReport accumulates differences found by xmlDiff.
+type xmlReport struct {
+	diffs    []string
+	distance int
+}
+
+func (r *xmlReport) add(weight int, format string, args ...any) {
+	r.diffs = append(r.diffs, fmt.Sprintf(format, args...))
+	r.distance += weight
+}
+
+// xmlDiff adds to r the differences between got and expected. Each
+// difference weighs 1, except when an element is replaced by another
+// one or is missing, where the whole expected tree weighs.
+func xmlDiff(r *xmlReport, path string, got, expected *xmlNode) {
+	if got.name != expected.name {
+		r.add(expected.size(), "%s: got element %s, expected %s",
+			path, xmlNameString(got.name), xmlNameString(expected.name))
+		return
+	}
+
+	gi, ei := 0, 0
+	for gi < len(got.attrs) || ei < len(expected.attrs) {
+		switch {
+		case ei == len(expected.attrs) ||
+			(gi < len(got.attrs) && xmlNameString(got.attrs[gi].Name) < xmlNameString(expected.attrs[ei].Name)):
+			r.add(1, "%s/@%s: unexpected %q",
+				path, xmlNameString(got.attrs[gi].Name), got.attrs[gi].Value)
+			gi++
+		case gi == len(got.attrs) ||
+			xmlNameString(got.attrs[gi].Name) > xmlNameString(expected.attrs[ei].Name):
+			r.add(1, "%s/@%s: missing, expected %q",
+				path, xmlNameString(expected.attrs[ei].Name), expected.attrs[ei].Value)
+			ei++
+		default:
+			if got.attrs[gi].Value != expected.attrs[ei].Value {
+				r.add(1, "%s/@%s: got %q, expected %q",
+					path, xmlNameString(got.attrs[gi].Name), got.attrs[gi].Value, expected.attrs[ei].Value)
+			}
+			gi++
+			ei++
+		}
+	}
+
+	if got.text != expected.text {
+		r.add(1, "%s: got text %q, expected %q",
+			path, got.text, expected.text)
+	}
+
+	if len(got.children) != len(expected.children) {
+		weight := 1
+		for i := len(got.children); i < len(expected.children); i++ {
+			weight += expected.children[i].size()
+		}
+		r.add(weight, "%s: got %d child elements, expected %d",
+			path, len(got.children), len(expected.children))
+	}
+	for i := 0; i < len(got.children) && i < len(expected.children); i++ {
+		xmlDiff(r, xmlChildPath(path, expected, i), got.children[i], expected.children[i])
+	}
+}
+
+// xmlPathStep is one step of a path parsed by parseXMLPath.
+type xmlPathStep struct {
+	descendant bool   // step preceded by "//"
+	name       string // local name or "*"
+	space      string // namespace URI, "" means any
+	pos        int    // 1-based position, 0 means any
+}
+
+// parseXMLPath parses an XPath-like path as "/Envelope/Body/User[2]/@id".
+// It returns the element steps and the final attribute name, if any.
+// A panic occurs if path cannot be parsed, prefixed by caller.
+func parseXMLPath(caller, path string) (steps []xmlPathStep, attr string) {
+	bad := func() {
+		panic(fmt.Sprintf("%s bad XML path %q", caller, path))
+	}
+	if !strings.HasPrefix(path, "/") {
+		bad()
+	}
+
+	p := path
+	for p != "" {
+		var step xmlPathStep
+		if strings.HasPrefix(p, "//") {
+			step.descendant = true
+			p = p[2:]
+		} else {
+			p = p[1:]
+		}
+
+		end := strings.IndexByte(p, '/')
+		if strings.HasPrefix(p, "{") {
+			// {namespace}local, namespace can contain "/"
+			rbrace := strings.IndexByte(p, '}')
+			if rbrace < 0 {
+				bad()
+			}
+			step.space = p[1:rbrace]
+			p = p[rbrace+1:]
+			end = strings.IndexByte(p, '/')
+		}
+		if end < 0 {
+			end = len(p)
+		}
+		seg := p[:end]
+		p = p[end:]
+
+		if strings.HasPrefix(seg, "@") {
+			if p != "" || step.descendant || step.space != "" || len(seg) == 1 {
+				bad()
+			}
+			return steps, seg[1:]
+		}
+
+		if open := strings.IndexByte(seg, '['); open >= 0 {
+			if !strings.HasSuffix(seg, "]") {
+				bad()
+			}
+			pos, err := strconv.Atoi(seg[open+1 : len(seg)-1])
+			if err != nil || pos < 1 {
+				bad()
+			}
+			step.pos = pos
+			seg = seg[:open]
+		}
+		if seg == "" {
+			bad()
+		}
+		step.name = seg
+		steps = append(steps, step)
+	}
+	return steps, ""
+}
+
+func (s xmlPathStep) matches(n *xmlNode) bool {
+	return (s.name == "*" || s.name == n.name.Local) &&
+		(s.space == "" || s.space == n.name.Space)
+}
+
+// xmlSelect returns the nodes of the tree rooted at root targeted by
+// steps.
+func xmlSelect(root *xmlNode, steps []xmlPathStep) []*xmlNode {
+	// Virtual document node, parent of root
+	nodes := []*xmlNode{{children: []*xmlNode{root}}}
+	for _, step := range steps {
+		var next []*xmlNode
+		for _, n := range nodes {
+			var candidates []*xmlNode
+			if step.descendant {
+				var walk func(*xmlNode)
+				walk = func(n *xmlNode) {
+					for _, c := range n.children {
+						if step.matches(c) {
+							candidates = append(candidates, c)
+						}
+						walk(c)
+					}
+				}
+				walk(n)
+			} else {
+				for _, c := range n.children {
+					if step.matches(c) {
+						candidates = append(candidates, c)
+					}
+				}
+			}
+			if step.pos > 0 {
+				if step.pos > len(candidates) {
+					continue
+				}
+				candidates = candidates[step.pos-1 : step.pos]
+			}
+			next = append(next, candidates...)
+		}
+		nodes = next
+	}
+	return nodes
+}
+
+// xmlSelectValues returns the trimmed texts of nodes targeted by
+// steps, or the values of their attr attribute if attr is not empty.
+func xmlSelectValues(root *xmlNode, steps []xmlPathStep, attr string) []string {
+	var values []string
+	for _, n := range xmlSelect(root, steps) {
+		if attr == "" {
+			values = append(values, n.text)
+			continue
+		}
+		for _, a := range n.attrs {
+			if a.Name.Local == attr {
+				values = append(values, a.Value)
+				break
+			}
+		}
+	}
+	return values
+}
+
+// newXMLMatcher returns a [Matcher] decoding request body as XML,
+// then calling check to get the differences with the expected value.
+// The request is matched if there is no difference.
+func newXMLMatcher(check func(got *xmlNode) *xmlReport) Matcher {
+	diff := func(req *http.Request) (int, string) {
+		body, err := readDecodedBody(req)
+		var got *xmlNode
+		if err == nil {
+			got, err = decodeXML(body)
+		}
+		if err != nil {
+			return maxMatcherDistance, "/: body is not valid XML: " + err.Error()
+		}
+		r := check(got)
+		return r.distance, strings.Join(r.diffs, "\n")
+	}
+	m := NewMatcher("", func(req *http.Request) bool {
+		distance, _ := diff(req)
+		return distance == 0
+	})
+	m.diff = diff
+	return m
+}
+
+// XMLBodyIs returns a [Matcher] checking that request body is an XML
+// document structurally equal to expected. Elements and attributes
+// are compared using their namespace URI and local name, so
+// namespace prefixes do not matter. Attributes order, namespace
+// declarations, comments, processing instructions and leading and
+// trailing whitespaces of texts are ignored.
+//
+// expected can be an XML document as string or []byte, or any other
+// value that is first encoded using [xml.Marshal]. If it cannot be
+// encoded or decoded, a panic occurs.
+//
+// When no responder is found, the differences between the request
+// body and expected are reported in the returned error, if this
+// [Matcher] is the closest one.
+//
+// The name of the returned [Matcher] is auto-generated (see [NewMatcher]).
+// To name it explicitly, use [Matcher.WithName] as in:
+//
+//	XMLBodyIs(`<user id="12"><name>Bob</name></user>`).WithName("10-xml-bob")
+func XMLBodyIs(expected any) Matcher {
+	var (
+		b   []byte
+		err error
+	)
+	switch e := expected.(type) {
+	case string:
+		b = []byte(e)
+	case []byte:
+		b = e
+	default:
+		b, err = xml.Marshal(e)
+	}
+	var exp *xmlNode
+	if err == nil {
+		exp, err = decodeXML(b)
+	}
+	if err != nil {
+		panic("XMLBodyIs bad expected XML: " + err.Error())
+	}
+
+	return newXMLMatcher(func(got *xmlNode) *xmlReport {
+		var r xmlReport
+		xmlDiff(&r, "/"+xmlNameString(exp.name), got, exp)
+		return &r
+	})
+}
+
+// XMLPathIs returns a [Matcher] checking that request body is an XML
+// document in which at least one of the values targeted by path is
+// expected. The value of an element is its text, without leading
+// and trailing whitespaces.
+//
+// path is an XPath-like expression, made of:
+//   - "/name" for a child element, "//name" for a descendant one;
+//   - "*" as name matches any element;
+//   - elements are matched by local name, whatever their namespace,
+//     except if prefixed by "{namespace-uri}" as in
+//     "/{http://schemas.xmlsoap.org/soap/envelope/}Envelope";
+//   - "[n]" after a name selects the n-th matching element, starting at 1;
+//   - a final "/@attr" selects the attr attribute.
+//
+// A panic occurs if path cannot be parsed.
+//
+// The name of the returned [Matcher] is auto-generated (see [NewMatcher]).
+// To name it explicitly, use [Matcher.WithName] as in:
+//
+//	XMLPathIs("/Envelope/Body/GetUser/@id", "12").WithName("10-get-user-12")
+func XMLPathIs(path, expected string) Matcher {
+	steps, attr := parseXMLPath("XMLPathIs", path)
+	return newXMLMatcher(func(got *xmlNode) *xmlReport {
+		var r xmlReport
+		values := xmlSelectValues(got, steps, attr)
+		if len(values) == 0 {
+			r.add(1, "%s: not found", path)
+			return &r
+		}
+		for _, v := range values {
+			if v == expected {
+				return &r
+			}
+		}
+		quoted := make([]string, len(values))
+		for i, v := range values {
+			quoted[i] = strconv.Quote(v)
+		}
+		r.add(1, "%s: got %s, expected %q", path, strings.Join(quoted, ", "), expected)
+		return &r
+	})
+}
+
+// XMLPathExists returns a [Matcher] checking that request body is an
+// XML document containing at least one element or attribute targeted
+// by path. See [XMLPathIs] for path syntax.
+//
+// The name of the returned [Matcher] is auto-generated (see [NewMatcher]).
+// To name it explicitly, use [Matcher.WithName] as in:
+//
+//	XMLPathExists("//Fault").WithName("10-soap-fault")
+func XMLPathExists(path string) Matcher {
+	steps, attr := parseXMLPath("XMLPathExists", path)
+	return newXMLMatcher(func(got *xmlNode) *xmlReport {
+		var r xmlReport
+		if len(xmlSelectValues(got, steps, attr)) == 0 {
+			r.add(1, "%s: not found", path)
+		}
+		return &r
+	})
+}
@@ -0,0 +1,125 @@
+package httpmock_test
+
+import (
+	"encoding/xml"
+	"io/ioutil" //nolint: staticcheck
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/td"
+
+	"github.com/jarcoal/httpmock"
+)
+
+func TestXMLMatchers(t *testing.T) {
+	assert := td.Assert(t)
+
+	const body = `<?xml version="1.0" encoding="UTF-8"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/" xmlns:u="urn:users">
+  <!-- a comment -->
+  <soap:Body>
+    <u:GetUser id="12" active="true">
+      <u:Name>  Bob  </u:Name>
+      <u:Tag>a</u:Tag>
+      <u:Tag>b</u:Tag>
+    </u:GetUser>
+  </soap:Body>
+</soap:Envelope>`
+
+	type user struct {
+		XMLName xml.Name `xml:"user"`
+		ID      int      `xml:"id,attr"`
+		Name    string   `xml:"name"`
+	}
+
+	for _, tc := range []struct {
+		name     string
+		matcher  httpmock.Matcher
+		body     string
+		expected bool
+	}{
+		{"is", httpmock.XMLBodyIs(body), body, true},
+		{
+			name: "is+other-prefixes",
+			matcher: httpmock.XMLBodyIs(`<Envelope xmlns="http://schemas.xmlsoap.org/soap/envelope/"><Body>` +
+				`<x:GetUser xmlns:x="urn:users" active="true" id="12"><x:Name>Bob</x:Name><x:Tag>a</x:Tag><x:Tag>b</x:Tag></x:GetUser>` +
+				`</Body></Envelope>`),
+			body:     body,
+			expected: true,
+		},
+		{
+			name: "is+other-namespace",
+			matcher: httpmock.XMLBodyIs(`<Envelope xmlns="http://schemas.xmlsoap.org/soap/envelope/"><Body>` +
+				`<x:GetUser xmlns:x="urn:people" active="true" id="12"><x:Name>Bob</x:Name><x:Tag>a</x:Tag><x:Tag>b</x:Tag></x:GetUser>` +
+				`</Body></Envelope>`),
+			body:     body,
+			expected: false,
+		},
+		{"is+go-value", httpmock.XMLBodyIs(user{ID: 12, Name: "Bob"}), `<user id="12"> <name>Bob</name> </user>`, true},
+		{"is+go-value-differ", httpmock.XMLBodyIs(user{ID: 13, Name: "Bob"}), `<user id="12"><name>Bob</name></user>`, false},
+		{"is+bytes", httpmock.XMLBodyIs([]byte(`<a><b/></a>`)), `<a><b></b></a>`, true},
+		{"is+not-xml", httpmock.XMLBodyIs(`<a/>`), `{"a":1}`, false},
+		{"is+2-roots", httpmock.XMLBodyIs(`<a/>`), `<a/><a/>`, false},
+		{"path", httpmock.XMLPathIs("/Envelope/Body/GetUser/Name", "Bob"), body, true},
+		{"path+attr", httpmock.XMLPathIs("/Envelope/Body/GetUser/@id", "12"), body, true},
+		{"path+descendant", httpmock.XMLPathIs("//GetUser/Tag", "b"), body, true},
+		{"path+pos", httpmock.XMLPathIs("//Tag[1]", "b"), body, false},
+		{"path+pos2", httpmock.XMLPathIs("//Tag[2]", "b"), body, true},
+		{"path+wildcard", httpmock.XMLPathIs("/*/*/*/@active", "true"), body, true},
+		{"path+ns", httpmock.XMLPathIs("/{http://schemas.xmlsoap.org/soap/envelope/}Envelope//{urn:users}Name", "Bob"), body, true},
+		{"path+bad-ns", httpmock.XMLPathIs("//{urn:people}Name", "Bob"), body, false},
+		{"exists", httpmock.XMLPathExists("//GetUser"), body, true},
+		{"exists+no", httpmock.XMLPathExists("//Fault"), body, false},
+		{"exists+attr-no", httpmock.XMLPathExists("//GetUser/@name"), body, false},
+	} {
+		assert.Run(tc.name, func(assert *td.T) {
+			req, err := http.NewRequest("POST", "http://z.tld/", strings.NewReader(tc.body))
+			td.Require(t).CmpNoError(err)
+			assert.Cmp(tc.matcher.Check(req), tc.expected)
+
+			// body is still readable
+			b, err := ioutil.ReadAll(req.Body)
+			assert.CmpNoError(err)
+			assert.Cmp(string(b), tc.body)
+		})
+	}
+
+	assert.CmpPanic(func() { httpmock.XMLBodyIs(`<a>`) },
+		td.HasPrefix("XMLBodyIs bad expected XML: "))
+	assert.CmpPanic(func() { httpmock.XMLBodyIs(func() {}) },
+		td.HasPrefix("XMLBodyIs bad expected XML: "))
+	for _, path := range []string{"", "a", "/", "/a//", "/a[0]", "/a[x", "/@id/b", "//@id", "/{ns", "/a/@"} {
+		assert.CmpPanic(func() { httpmock.XMLPathExists(path) },
+			`XMLPathExists bad XML path "`+path+`"`, path)
+	}
+}
+
+func TestXMLMatcherDiff(t *testing.T) {
+	assert := td.Assert(t)
+
+	mt := httpmock.NewMockTransport()
+	client := &http.Client{Transport: mt}
+
+	mt.RegisterMatcherResponder("POST", "/soap",
+		httpmock.XMLBodyIs(`<Order id="1" xmlns="urn:shop"><Item qty="2">apple</Item><Item>pear</Item></Order>`).
+			WithName("00-order"),
+		httpmock.NewStringResponder(200, "OK"))
+
+	_, err := client.Post("http://z.tld/soap", "text/xml",
+		strings.NewReader(`<o:Order xmlns:o="urn:shop" id="1" at="now"><o:Item qty="3">apple</o:Item><o:Item>peach</o:Item></o:Order>`))
+	assert.HasSuffix(err, `despite matcher "00-order", closest matcher "00-order" differs:
+	/{urn:shop}Order/@at: unexpected "now"
+	/{urn:shop}Order/{urn:shop}Item[1]/@qty: got "3", expected "2"
+	/{urn:shop}Order/{urn:shop}Item[2]: got text "peach", expected "pear"`)
+
+	// A root element mismatch weighs more than a path check failure
+	mt.RegisterMatcherResponder("POST", "/soap",
+		httpmock.XMLPathIs("/Cancel/@id", "1").WithName("01-cancel"),
+		httpmock.NewStringResponder(200, "OK"))
+
+	_, err = client.Post("http://z.tld/soap", "text/xml",
+		strings.NewReader(`<Cancel id="2"/>`))
+	assert.HasSuffix(err, `closest matcher "01-cancel" differs:
+	/Cancel/@id: got "2", expected "1"`)
+}